@@ -2,13 +2,20 @@ package game // Déclare le package "game", qui contient tous les éléments du
 
 import (
 	"fmt"           // Pour formater du texte (ex: fmt.Sprintf)
-	"math/rand"     // Pour générer des nombres aléatoires
+	"image/color"   // Pour griser les attaques impossibles
+	"math/rand"     // Pour choisir les dialogues (purement cosmétique)
 	"path/filepath" // Pour créer des chemins de fichiers portables
 	"strconv"       // Pour convertir des int en string
+	"strings"       // Pour le nom de l'adversaire dans les punchlines
 	"time"          // Pour gérer durées et timestamps
 
 	"github.com/hajimehoshi/ebiten/v2"            // Ebiten, moteur 2D
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil" // Pour afficher texte et debug facilement
+	"github.com/hajimehoshi/ebiten/v2/inpututil"  // Pour détecter les appuis uniques
+	"github.com/hajimehoshi/ebiten/v2/text"       // Pour le menu en couleur
+	"github.com/hajimehoshi/ebiten/v2/vector"     // Pour la zone d'écriture du freestyle
+	"golang.org/x/image/font"                     // Polices pour les effets de combat
+	"golang.org/x/image/font/basicfont"           // Police du menu
 )
 
 // LoadAnimation charge une série d’images pour une animation
@@ -21,17 +28,78 @@ func LoadAnimation(prefix string, count int) []*ebiten.Image {
 	return frames // Retourne les frames chargées
 }
 
+// BattleState est l'étape courante de la machine à états du combat
+type BattleState int
+
+const (
+	BattleChooseMove BattleState = iota // Le joueur choisit son attaque dans le menu
+	BattlePlayerAnim                    // Animation d'attaque du joueur
+	BattleEnemyAnim                     // Animation d'attaque de l'ennemi
+	BattleResolve                       // Application des dégâts de l'attaque qui vient d'être jouée
+	BattleVictory                       // L'ennemi est KO (animation de mort puis attente d'Entrée)
+	BattleDefeat                        // Le joueur est KO (animation de mort puis attente d'Entrée)
+	BattleAborted                       // Combat interrompu (déconnexion, désynchronisation...)
+	BattleFreestyle                     // Un camp écrit son freestyle au clavier
+)
+
+// String retourne le nom de l'état (pratique pour les logs)
+func (s BattleState) String() string {
+	switch s {
+	case BattleChooseMove:
+		return "choose_move"
+	case BattlePlayerAnim:
+		return "player_anim"
+	case BattleEnemyAnim:
+		return "enemy_anim"
+	case BattleResolve:
+		return "resolve"
+	case BattleVictory:
+		return "victory"
+	case BattleDefeat:
+		return "defeat"
+	case BattleAborted:
+		return "aborted"
+	case BattleFreestyle:
+		return "freestyle"
+	}
+	return "unknown"
+}
+
+// Nombre de ticks (60 par seconde) par frame d'animation, soit 150ms
+const battleFrameTicks = 9
+
+// battleInput regroupe les appuis clavier globaux lus pour une frame
+// (le choix des attaques passe par les BattleController)
+type battleInput struct {
+	Confirm   bool   // Entrée
+	Cancel    bool   // ESC
+	Chars     []rune // Caractères tapés (freestyle)
+	Backspace bool   // Effacer (freestyle)
+}
+
+// readBattleInput lit les touches qui viennent d'être pressées (un seul déclenchement par appui)
+func readBattleInput() battleInput {
+	return battleInput{
+		Confirm:   inpututil.IsKeyJustPressed(ebiten.KeyEnter),
+		Cancel:    inpututil.IsKeyJustPressed(ebiten.KeyEscape),
+		Chars:     ebiten.InputChars(),
+		Backspace: inpututil.IsKeyJustPressed(ebiten.KeyBackspace),
+	}
+}
+
 // Battle contient toutes les infos d’un combat
 type Battle struct {
 	bg *ebiten.Image // Image de fond
 
-	playerEgo int // Ego joueur
-	enemyEgo  int // Ego ennemi
+	combat        *Combat    // Moteur de règles (ego, seed, journal des événements)
+	startFighters [2]Fighter // Combattants au début du combat (pour le journal)
 
-	menuOptions    []string // Options du menu de combat
-	selectedOption int      // Option sélectionnée
-	attackDamages  []int    // Dégâts attaques joueur
-	enemyDamages   []int    // Dégâts attaques ennemies
+	state     BattleState // Étape courante du combat
+	chooser   Side        // Camp qui choisit son attaque pendant BattleChooseMove
+	resolving Side        // Camp dont l'attaque est appliquée pendant BattleResolve
+
+	controllers [2]BattleController // Qui choisit les attaques de chaque camp
+	lastMove    [2]int              // Dernière attaque choisie par chaque camp
 
 	// Animations
 	playerIdle *ebiten.Image // Sprite idle joueur
@@ -47,70 +115,149 @@ type Battle struct {
 	// Animation contrôle
 	currentFrames []*ebiten.Image // Frames actuellement jouées
 	currentIndex  int             // Index frame courante
-	animStart     time.Time       // Timestamp début animation
-	animSpeed     time.Duration   // Délai entre frames
-	animPlaying   bool            // Animation en cours ?
-	attacker      string          // Qui attaque ("player", "enemy", "dead_player"...)
+	frameTick     int             // Ticks écoulés sur la frame courante
+	speed         BattleSpeed     // Vitesse de lecture (réglages)
 
-	// IA
-	lastPlayerAttack int // Dernière attaque sélectionnée par le joueur
+	// Modes
+	replay bool // Combat rejoué depuis le journal ?
+	versus bool // Deux joueurs humains sur le même clavier ?
 
 	// Dialogues
-	playerLines    [][]string    // Lignes de dialogues joueur
-	enemyLines     [][]string    // Lignes de dialogues IA
-	currentLine    string        // Ligne affichée
-	lineStart      time.Time     // Début affichage dialogue
-	lineDuration   time.Duration // Durée affichage
-	dialogCooldown time.Duration // Délai entre dialogues
-	lastDialogTime time.Time     // Dernier dialogue affiché
+	dialogue    *Dialogue   // Répliques (assets/dialogue.json, puis générateur de punchlines)
+	items       [2][]string // Objets de chaque camp (cités par les punchlines générées)
+	currentLine string      // Ligne affichée
+	lineSide    Side        // Camp qui la dit (bulle au-dessus de lui)
+	lastLine    [2]string   // Dernière réplique de chaque camp (le freestyle doit rimer avec)
+
+	// Freestyle en cours d'écriture
+	freestyleSide   Side          // Camp qui écrit
+	freestylePrompt string        // Phrase avec laquelle rimer
+	freestyleText   string        // Texte tapé
+	freestyleTicks  int           // Ticks écoulés depuis le début
+	lineStart       time.Time     // Début affichage dialogue
+	lineDuration    time.Duration // Durée affichage
+	dialogCooldown  time.Duration // Délai entre dialogues
+	lastDialogTime  time.Time     // Dernier dialogue affiché
+
+	// Effets visuels (barres, dégâts flottants, tremblement...)
+	fx        *battleFX     // Effets déclenchés par les événements du moteur
+	canvas    *ebiten.Image // Image intermédiaire utilisée pendant un tremblement
+	fontSmall font.Face     // Police des barres d'ego
+	fontBig   font.Face     // Police des dégâts et de la bannière
 
 	// Gestion mort + sortie
 	deadFinished  bool          // Animation mort terminée ?
-	deadFrame     *ebiten.Image // Dernière frame de mort
 	endMsg        *ebiten.Image // Image fin combat
 	exitRequested bool          // Sortie demandée ?
+	abortMsg      string        // Raison de l'interruption du combat
 
 	Winner string // "player" ou "enemy"
 }
 
 // NewBattle initialise un combat avec un joueur et un ennemi
 func NewBattle(player *Player, enemy *Enemy) *Battle {
-	egoFinal := player.Ego // Ego de base joueur
-	if player != nil {
-		egoFinal += player.BonusEgo // Ajouter bonus temporaire
-		player.BonusEgo = 0         // Réinitialiser bonus
+	seed := uint64(time.Now().UnixNano()) // Seed du combat, conservée dans le journal
+	stats := player.Stats()               // Stats équipement compris
+	c := NewCombat(seed,
+		Fighter{Name: "Joueur", Ego: player.Ego, Class: player.class, Flow: stats.Flow, MaxFlow: stats.Flow, Charisma: stats.Charisma, CritBonus: stats.CritBonus()},
+		Fighter{Name: enemy.Name, Ego: enemy.Ego, Flow: enemy.Flow, MaxFlow: enemy.Flow},
+	)
+	b := newBattleScene(c)
+	b.controllers = [2]BattleController{NewHumanController(KeysArrow), NewAIController(seed)}
+	c.EnableFreestyle(SidePlayer) // Le joueur écrit ses freestyles au clavier
+	if enemy.Boss {
+		b.UseLookaheadAI()
 	}
 
-	enemyEgo := enemy.Ego // Ego de base ennemi
-	if player != nil && player.PendingEnemyEgoDebuff > 0 {
-		enemyEgo -= player.PendingEnemyEgoDebuff // Appliquer malus
-		if enemyEgo < 0 {
-			enemyEgo = 0 // Minimum 0
-		}
+	if player.BonusEgo > 0 {
+		c.ApplyStatus(SidePlayer, "bonus_ego", player.BonusEgo) // Ajouter bonus temporaire
+		player.BonusEgo = 0                                     // Réinitialiser bonus
+	}
+	if player.PendingEnemyEgoDebuff > 0 {
+		c.ApplyStatus(SideEnemy, "ego_debuff", -player.PendingEnemyEgoDebuff) // Appliquer malus
 		player.PendingEnemyEgoDebuff = 0
 	}
 
+	return b
+}
+
+// NewReplayBattle recrée un combat enregistré : mêmes combattants, même seed, mêmes attaques
+func NewReplayBattle(rec BattleRecord) *Battle {
+	b := newBattleScene(NewCombat(rec.Seed, rec.Player, rec.Enemy))
+	b.replay = true
+	b.controllers = [2]BattleController{
+		NewReplayController(rec.Events, SidePlayer),
+		NewReplayController(rec.Events, SideEnemy),
+	}
+
+	// Statuts de début de combat
+	for _, ev := range rec.Events {
+		if ev.Type == EventStatus {
+			b.combat.ApplyStatus(ev.Target, ev.Status, ev.Amount)
+		}
+	}
+
+	return b
+}
+
+// NewVersusBattle crée un combat entre deux personnages de sauvegarde, chacun joué au clavier
+func NewVersusBattle(left, right Save) *Battle {
+	b := newVersusBattle(uint64(time.Now().UnixNano()), left, right)
+	b.controllers = [2]BattleController{NewHumanController(KeysLeft), NewHumanController(KeysArrow)}
+	b.combat.EnableFreestyle(SidePlayer)
+	b.combat.EnableFreestyle(SideEnemy)
+	return b
+}
+
+// newVersusBattle prépare un combat entre deux sauvegardes, sans ses contrôleurs
+func newVersusBattle(seed uint64, left, right Save) *Battle {
+	c := NewCombat(seed, versusFighter(left), versusFighter(right))
+	b := newBattleScene(c)
+	b.versus = true
+
+	// Les objets des sauvegardes donnent leurs bonus sans être consommés
+	for side, s := range []Save{left, right} {
+		b.SetItems(Side(side), s.Inventory.IDs())
+		bonus, debuff := ItemBattleBonuses(s.Inventory.IDs())
+		if bonus > 0 {
+			c.ApplyStatus(Side(side), "bonus_ego", bonus)
+		}
+		if debuff > 0 {
+			c.ApplyStatus(Side(side).Other(), "ego_debuff", -debuff)
+		}
+	}
+
+	return b
+}
+
+// versusFighter construit un combattant à partir d'une sauvegarde (équipement compris)
+func versusFighter(s Save) Fighter {
+	bonus := s.Equipment.Bonus()
+	ego := s.Ego
+	if ego <= 0 {
+		ego = 100 + bonus.Ego // Un perso KO dans sa partie revient en forme pour le versus
+	}
+	flow := s.Flow + bonus.Flow
+	return Fighter{
+		Name: fmt.Sprintf("%s (%s)", s.Name, s.Class), Ego: ego, Class: s.Class, Flow: flow, MaxFlow: flow,
+		Charisma: s.Charisma + bonus.Charisma, CritBonus: bonus.CritBonus(),
+	}
+}
+
+// newBattleScene charge les ressources graphiques et les dialogues autour d'un moteur de combat
+func newBattleScene(c *Combat) *Battle {
 	// Crée la structure Battle
 	b := &Battle{
-		bg:               LoadImage("assets/battle_bg.png"),           // Fond combat
-		playerEgo:        egoFinal,                                    // Ego joueur
-		enemyEgo:         enemyEgo,                                    // Ego ennemi
-		menuOptions:      []string{"Punchline", "Flow", "Diss Track"}, // Menu
-		attackDamages:    []int{10, 5, 30},                            // Dégâts joueur
-		enemyDamages:     []int{10, 5, 30},                            // Dégâts ennemis
-		animSpeed:        150 * time.Millisecond,                      // Vitesse animations
-		animPlaying:      false,
-		currentIndex:     0,
-		deadFinished:     false,
-		selectedOption:   0,
-		lastPlayerAttack: 0,
-		attacker:         "",
-		lineDuration:     2000 * time.Millisecond,          // 2s affichage dialogues
-		dialogCooldown:   2500 * time.Millisecond,          // 2,5s entre dialogues
-		lastDialogTime:   time.Now().Add(-2 * time.Second), // Permet dialogue immédiat
-	}
-
-	rand.Seed(time.Now().UnixNano()) // Initialiser RNG
+		bg:             LoadImage("assets/battle_bg.png"), // Fond combat
+		combat:         c,                                 // Moteur de règles
+		startFighters:  c.Fighters,                        // Copie de l'état initial
+		state:          BattleChooseMove,                  // Le plus rapide commence
+		chooser:        c.Order()[0],
+		lineDuration:   2000 * time.Millisecond,          // 2s affichage dialogues
+		dialogCooldown: 2500 * time.Millisecond,          // 2,5s entre dialogues
+		lastDialogTime: time.Now().Add(-2 * time.Second), // Permet dialogue immédiat
+		fx:             newBattleFX(c),                   // Effets visuels
+	}
 
 	// Idle
 	b.playerIdle = LoadImage("assets/player_idle.png")
@@ -128,19 +275,8 @@ func NewBattle(player *Player, enemy *Enemy) *Battle {
 	b.playerDead = LoadAnimation("player_dead", 5)
 	b.enemyDead = LoadAnimation("enemy_dead", 5)
 
-	// Dialogues joueur
-	b.playerLines = [][]string{
-		{"Yo je te pète la rime !", "C’est chaud comme le freestyle !"},    // Punchline
-		{"Mon flow te fait trembler !", "Tu peux pas suivre mon rythme !"}, // Flow
-		{"Diss track incoming ! je vais ruiner ta carrière !"},             // Diss Track
-	}
-
-	// Dialogues IA
-	b.enemyLines = [][]string{
-		{"Tu crois pouvoir me punchliner ?", "J'te mets KO avec mes rimes !"}, // Punchline
-		{"Mon flow est supérieur !", "Trop lent pour moi !"},                  // Flow
-		{"Diss Track ! je vais te faire regretter !"},                         // Diss Track
-	}
+	// Dialogues (joueur et IA)
+	b.dialogue = LoadDialogue(dialoguePath, NewPunchlineGen(c.Seed))
 
 	// Image de fin
 	b.endMsg = LoadImage("assets/combat_end.png")
@@ -151,166 +287,373 @@ func NewBattle(player *Player, enemy *Enemy) *Battle {
 	return b // Retourne la structure initialisée
 }
 
-// ChooseEnemyAttack sélectionne aléatoirement l’attaque ennemie
-func (b *Battle) ChooseEnemyAttack() int {
-	r := rand.Intn(100) // 0-99
-	if r < 50 {         // 50%
-		return 0 // Punchline
-	} else if r < 80 { // 30%
-		return 1 // Flow
+// SetFonts définit les polices utilisées par les effets du combat
+func (b *Battle) SetFonts(small, big font.Face) {
+	b.fontSmall = small
+	b.fontBig = big
+}
+
+// SetReducedMotion active ou non le mode mouvements réduits (pas de tremblement ni de flash)
+func (b *Battle) SetReducedMotion(reduced bool) {
+	b.fx.reducedMotion = reduced
+}
+
+// SetSpeed règle la vitesse des animations et des dialogues, pour les deux camps
+func (b *Battle) SetSpeed(speed BattleSpeed) {
+	b.speed = speed
+	if f := speed.Factor(); f > 0 {
+		b.lineDuration = 2000 * time.Millisecond / time.Duration(f)
+		b.dialogCooldown = 2500 * time.Millisecond / time.Duration(f)
+	}
+}
+
+// SetItems indique les objets d'un camp, que ses punchlines peuvent citer
+func (b *Battle) SetItems(side Side, items []string) {
+	b.items[side] = nil
+	for _, item := range items {
+		b.items[side] = append(b.items[side], ItemName(item))
+	}
+}
+
+// UseLookaheadAI fait jouer l'ennemi avec l'IA qui anticipe (boss, mode difficile)
+func (b *Battle) UseLookaheadAI() {
+	b.controllers[SideEnemy] = NewLookaheadController(b.combat.Seed, DefaultLookahead)
+}
+
+// State retourne l'étape courante du combat
+func (b *Battle) State() BattleState {
+	return b.state
+}
+
+// playAnimation démarre une nouvelle animation depuis sa première frame
+func (b *Battle) playAnimation(frames []*ebiten.Image) {
+	b.currentFrames = frames
+	b.currentIndex = 0
+	b.frameTick = 0
+}
+
+// stepAnimation avance l'animation d'un tick et retourne true quand elle est terminée
+func (b *Battle) stepAnimation() bool {
+	// Mode instantané : on saute directement à la dernière frame
+	if b.speed == SpeedInstant {
+		b.currentIndex = max(len(b.currentFrames)-1, 0)
+		return true
+	}
+	b.frameTick++
+	if b.frameTick < battleFrameTicks/b.speed.Factor() {
+		return false
+	}
+	b.frameTick = 0
+	if b.currentIndex+1 >= len(b.currentFrames) {
+		return true // On reste sur la dernière frame
+	}
+	b.currentIndex++
+	return false
+}
+
+// LaunchAttack enregistre l'attaque choisie par un camp et démarre son animation
+func (b *Battle) LaunchAttack(side Side, move int) {
+	b.lastMove[side] = move
+	b.combat.ChooseMove(side, move)
+
+	if side == SidePlayer { // Joueur (à gauche) attaque
+		b.state = BattlePlayerAnim
+		b.playAnimation(b.playerAtk)
+	} else { // Ennemi (à droite) attaque
+		b.state = BattleEnemyAnim
+		b.playAnimation(b.enemyAtk)
+	}
+	if move == MoveFreestyle {
+		return // Le camp dit le texte qu'il a écrit (voir finishFreestyle)
+	}
+
+	// Situations facultatives : premier tour, ego bas
+	q := b.dialogueQuery(side, move, "")
+	if b.combat.Turn == 1 {
+		q.Situations = append(q.Situations, CtxFirstTurn)
+	}
+	if start := b.startFighters[side].Ego; start > 0 && b.combat.Fighter(side).Ego*100 <= start*lowEgoPercent {
+		q.Situations = append(q.Situations, CtxLowEgo)
+	}
+	line, ok := b.dialogue.Pick(q)
+	if !ok {
+		line = moveDefs()[move].Name + " !" // Aucune réplique dans les données
+	}
+	b.say(side, line, false)
+}
+
+// startFreestyle ouvre l'écriture d'un freestyle ; en replay, le texte enregistré est rejoué
+func (b *Battle) startFreestyle(side Side) {
+	if src, ok := b.controllers[side].(*ReplayController); ok {
+		bar, score := src.NextBar()
+		b.finishFreestyle(side, bar, score)
+		return
+	}
+	b.state = BattleFreestyle
+	b.freestyleSide = side
+	b.freestyleText = ""
+	b.freestyleTicks = 0
+	// Rimer avec la dernière phrase de l'adversaire (ou une phrase imposée s'il n'a encore rien dit)
+	b.freestylePrompt = b.lastLine[side.Other()]
+	if b.freestylePrompt == "" && b.dialogue.gen != nil {
+		b.freestylePrompt = b.dialogue.gen.Line(PunchlineInput{Move: -1})
 	}
-	return 2 // 20% Diss Track
 }
 
-// LaunchAttack démarre l’animation d’une attaque
-func (b *Battle) LaunchAttack(attacker string) {
-	b.animPlaying = true     // Lancer animation
-	b.animStart = time.Now() // Timestamp début
-	b.currentIndex = 0       // Première frame
-	b.attacker = attacker    // Stocke attaquant
+// finishFreestyle lance le freestyle écrit avec sa note
+func (b *Battle) finishFreestyle(side Side, bar string, score int) {
+	b.LaunchAttack(side, MoveFreestyle)
+	b.combat.RecordBar(side, bar, score)
+	if bar != "" {
+		b.say(side, bar, true)
+	}
+}
+
+// dialogueQuery prépare la recherche d'une réplique pour un camp
+func (b *Battle) dialogueQuery(side Side, move int, context string) DialogueQuery {
+	speaker := SpeakerPlayer
+	// Nom de l'adversaire sans la classe ajoutée en versus ; le joueur solo n'a pas de vrai nom
+	opponent, _, _ := strings.Cut(b.combat.Fighter(side.Other()).Name, " (")
+	if side == SideEnemy && !b.versus {
+		speaker = SpeakerEnemy
+		opponent = ""
+	}
+	return DialogueQuery{
+		Side:     side,
+		Speaker:  speaker,
+		Move:     move,
+		Context:  context,
+		Enemy:    b.combat.Fighter(SideEnemy).Name,
+		Opponent: opponent,
+		Class:    b.combat.Fighter(side).Class,
+		Items:    b.items[side],
+	}
+}
 
+// say affiche une réplique dans la bulle d'un camp ; force ignore le délai entre dialogues
+func (b *Battle) say(side Side, line string, force bool) {
+	b.lastLine[side] = line
+	if b.speed == SpeedInstant { // Mode instantané : pas de dialogue
+		return
+	}
 	now := time.Now()
-	if now.Sub(b.lastDialogTime) >= b.dialogCooldown { // Si cooldown ok
-		if attacker == "player" { // Joueur attaque
-			b.currentFrames = b.playerAtk
-			b.lastPlayerAttack = b.selectedOption
-			lines := b.playerLines[b.selectedOption]
-			b.currentLine = lines[rand.Intn(len(lines))] // Ligne aléatoire
-		} else { // Ennemi attaque
-			idx := b.ChooseEnemyAttack()
-			b.currentFrames = b.enemyAtk
-			lines := b.enemyLines[idx]
-			b.currentLine = lines[rand.Intn(len(lines))]
-		}
+	if force || now.Sub(b.lastDialogTime) >= b.dialogCooldown { // Si cooldown ok
+		b.currentLine = line   // Réplique affichée
+		b.lineSide = side      // Bulle au-dessus du locuteur
 		b.lineStart = now      // Début affichage dialogue
 		b.lastDialogTime = now // Reset cooldown dialogue
-	} else if attacker == "player" {
-		b.currentFrames = b.playerAtk
-		b.lastPlayerAttack = b.selectedOption
-	} else {
-		b.currentFrames = b.enemyAtk
 	}
 }
 
-// LaunchDeath démarre animation mort et définit le gagnant
-func (b *Battle) LaunchDeath(who string) {
-	b.animPlaying = true
-	b.animStart = time.Now()
-	b.currentIndex = 0
+// sayContext fait réagir un camp à une situation (coup critique, fin du combat), s'il a une réplique pour elle
+func (b *Battle) sayContext(side Side, move int, context string) bool {
+	line, ok := b.dialogue.Pick(b.dialogueQuery(side, move, context))
+	if ok {
+		b.say(side, line, true)
+	}
+	return ok
+}
 
-	if who == "player" {
-		b.attacker = "dead_player"
-		b.currentFrames = b.playerDead
-		b.Winner = "enemy" // ✅ l'ennemi gagne
+// LaunchDeath démarre l'animation de mort du camp KO et définit le gagnant
+func (b *Battle) LaunchDeath(loser Side) {
+	if loser == SidePlayer {
+		b.state = BattleDefeat
+		b.playAnimation(b.playerDead)
 	} else {
-		b.attacker = "dead_enemy"
-		b.currentFrames = b.enemyDead
-		b.Winner = "player" // ✅ joueur gagne
+		b.state = BattleVictory
+		b.playAnimation(b.enemyDead)
+	}
+	b.Winner = loser.Other().String()
+	b.deadFinished = false
+
+	// Le gagnant savoure ou le perdant encaisse (au hasard ; l'autre parle si le premier n'a rien à dire)
+	first, second := loser.Other(), loser
+	firstCtx, secondCtx := CtxVictory, CtxDefeat
+	if rand.Intn(2) == 0 {
+		first, second = second, first
+		firstCtx, secondCtx = secondCtx, firstCtx
+	}
+	if !b.sayContext(first, -1, firstCtx) {
+		b.sayContext(second, -1, secondCtx)
 	}
 }
 
-func (b *Battle) Update() {
-	// Vérifie si le combat est terminé (mort + fin animation)
-	if b.deadFinished {
-		// Si le joueur appuie sur Enter, demande de sortie
-		if ebiten.IsKeyPressed(ebiten.KeyEnter) {
-			b.exitRequested = true
+// Abort interrompt le combat avec un message ; winner vaut "" s'il n'y a pas de gagnant
+func (b *Battle) Abort(msg, winner string) {
+	b.state = BattleAborted
+	b.abortMsg = msg
+	b.Winner = winner
+}
+
+// turnEnded prévient les contrôleurs qui suivent le combat qu'un tour est résolu
+func (b *Battle) turnEnded() {
+	for _, c := range b.controllers {
+		if obs, ok := c.(battleObserver); ok {
+			obs.TurnEnded(b)
 		}
-		// On ne fait rien d'autre si le combat est terminé
+	}
+}
+
+// Update lit le clavier puis fait avancer la machine à états
+func (b *Battle) Update() {
+	b.advance(readBattleInput())
+	b.fx.update(b.combat)
+}
+
+// advance fait avancer le combat d'un tick avec les entrées données
+func (b *Battle) advance(in battleInput) {
+	// En replay, ESC interrompt la lecture à tout moment
+	if b.replay && in.Cancel {
+		b.exitRequested = true
 		return
 	}
 
-	// Gestion des animations en cours (attaque ou mort)
-	if b.animPlaying {
-		// Vérifie si le temps écoulé depuis le début de l'animation est supérieur à la vitesse définie
-		if time.Since(b.animStart) > b.animSpeed {
-			// Réinitialise le temps de départ pour la frame suivante
-			b.animStart = time.Now()
-			// Passe à la frame suivante
-			b.currentIndex++
-			// Vérifie si on est arrivé à la fin de l'animation
-			if b.currentIndex >= len(b.currentFrames) {
-				// Selon qui effectue l'attaque ou la mort
-				switch b.attacker {
-				case "player":
-					// Dégâts infligés à l'ennemi
-					dmg := b.attackDamages[b.lastPlayerAttack]
-					b.enemyEgo -= dmg
-					// Vérifie si l'ennemi est mort
-					if b.enemyEgo <= 0 {
-						b.LaunchDeath("enemy") // Déclenche l'animation de mort de l'ennemi
-						return
-					}
-					// Sinon, fin de l'animation et préparation de l'attaque ennemie
-					b.animPlaying = false
-					b.currentIndex = 0
-					b.LaunchAttack("enemy")
-					return
-
-				case "enemy":
-					// Choix aléatoire de l'attaque ennemie
-					idx := b.ChooseEnemyAttack()
-					dmg := b.enemyDamages[idx]
-					b.playerEgo -= dmg
-					// Vérifie si le joueur est mort
-					if b.playerEgo <= 0 {
-						b.LaunchDeath("player") // Déclenche l'animation de mort du joueur
-						return
-					}
-					// Sinon, fin de l'animation et reset de l'attaquant
-					b.animPlaying = false
-					b.currentIndex = 0
-					b.attacker = ""
-
-				case "dead_player", "dead_enemy":
-					// Si animation de mort terminée
-					b.deadFinished = true
-					// Stocke la dernière frame de l'animation de mort
-					if len(b.currentFrames) > 0 {
-						b.deadFrame = b.currentFrames[len(b.currentFrames)-1]
-					}
-					// Fin de l'animation
-					b.animPlaying = false
-					b.currentIndex = 0
-				}
-			}
+	// Les contrôleurs réseau reçoivent les messages à chaque tick
+	for _, c := range b.controllers {
+		if obs, ok := c.(battleObserver); ok {
+			obs.Tick(b)
 		}
-		// Ne rien faire d'autre pendant l'animation
-		return
 	}
 
-	// Gestion de la sélection du menu joueur si personne n'attaque
-	if b.attacker == "" {
-		// Déplacement vers le bas dans le menu
-		if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
-			b.selectedOption++
-			// Bouclage sur la première option si on dépasse la fin
-			if b.selectedOption >= len(b.menuOptions) {
-				b.selectedOption = 0
+	switch b.state {
+	case BattleChooseMove:
+		// Le contrôleur du camp (clavier, IA ou replay) choisit l'attaque
+		if move, ok := b.controllers[b.chooser].ChooseMove(b, b.chooser); ok {
+			if move == MoveFreestyle {
+				b.startFreestyle(b.chooser)
+			} else {
+				b.LaunchAttack(b.chooser, move)
 			}
-			// Petit délai pour éviter plusieurs mouvements rapides
-			time.Sleep(150 * time.Millisecond)
 		}
-		// Déplacement vers le haut dans le menu
-		if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
-			b.selectedOption--
-			// Bouclage sur la dernière option si on dépasse le début
-			if b.selectedOption < 0 {
-				b.selectedOption = len(b.menuOptions) - 1
+
+	case BattleFreestyle:
+		// Écriture au clavier jusqu'à Entrée ou la fin du temps
+		for _, r := range in.Chars {
+			if len([]rune(b.freestyleText)) < freestyleMaxChars {
+				b.freestyleText += string(r)
 			}
-			time.Sleep(150 * time.Millisecond)
 		}
-		// Validation de l'attaque avec Enter
-		if ebiten.IsKeyPressed(ebiten.KeyEnter) {
-			b.LaunchAttack("player")
+		if in.Backspace && b.freestyleText != "" {
+			r := []rune(b.freestyleText)
+			b.freestyleText = string(r[:len(r)-1])
+		}
+		b.freestyleTicks++
+		if in.Confirm || b.freestyleTicks >= freestyleSeconds*60 {
+			bar := strings.TrimSpace(b.freestyleText)
+			b.finishFreestyle(b.freestyleSide, bar, ScoreBar(bar, b.freestylePrompt).Total)
 		}
+
+	case BattlePlayerAnim, BattleEnemyAnim:
+		// Fin de l'animation : on applique les dégâts au tick suivant
+		if b.stepAnimation() {
+			b.resolving = SidePlayer
+			if b.state == BattleEnemyAnim {
+				b.resolving = SideEnemy
+			}
+			b.state = BattleResolve
+		}
+
+	case BattleResolve:
+		for _, ev := range b.combat.ResolveMove(b.resolving, b.lastMove[b.resolving]) {
+			if ev.Type == EventDamage && ev.Crit && ev.Actor == b.resolving {
+				b.sayContext(b.resolving, ev.Move, CtxCrit)
+			}
+		}
+
+		target := b.resolving.Other()
+		switch {
+		case b.combat.Fighter(target).Ego <= 0:
+			b.turnEnded()
+			b.LaunchDeath(target) // Le camp touché est KO
+		case b.combat.Fighter(b.resolving).Ego <= 0:
+			b.turnEnded()
+			b.LaunchDeath(b.resolving) // Punchline renvoyée : l'attaquant est KO
+		case b.resolving == b.combat.Order()[0]:
+			b.chooser = b.resolving.Other() // L'autre camp répond
+			b.state = BattleChooseMove
+		default:
+			b.turnEnded()
+			b.combat.EndTurn() // Fin du tour : le plus rapide du nouveau tour choisit
+			b.chooser = b.combat.Order()[0]
+			b.state = BattleChooseMove
+		}
+
+	case BattleVictory, BattleDefeat:
+		if !b.deadFinished {
+			b.deadFinished = b.stepAnimation()
+			return
+		}
+		// Combat terminé : Entrée pour sortir
+		if in.Confirm {
+			b.exitRequested = true
+		}
+
+	case BattleAborted:
+		// Combat interrompu : Entrée pour sortir
+		if in.Confirm {
+			b.exitRequested = true
+		}
+	}
+}
+
+// drawSprite dessine une image à l'échelle du combat, teintée en rouge si flash
+func drawSprite(screen, img *ebiten.Image, x, y, scale float64, flash bool) {
+	if img == nil {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	if flash {
+		op.ColorScale.Scale(1, 0.35, 0.35, 1)
 	}
+	screen.DrawImage(img, op)
+}
+
+// activeSide retourne le camp qui agit (celui qui choisit, ou celui dont l'attaque est jouée)
+func (b *Battle) activeSide() Side {
+	switch b.state {
+	case BattlePlayerAnim:
+		return SidePlayer
+	case BattleEnemyAnim:
+		return SideEnemy
+	case BattleResolve:
+		return b.resolving
+	case BattleFreestyle:
+		return b.freestyleSide
+	}
+	return b.chooser
+}
+
+// currentFrame retourne la frame d'animation courante
+func (b *Battle) currentFrame() *ebiten.Image {
+	if len(b.currentFrames) == 0 {
+		return nil
+	}
+	idx := b.currentIndex
+	// S'assure qu'on ne dépasse pas le nombre de frames
+	if idx >= len(b.currentFrames) {
+		idx = len(b.currentFrames) - 1
+	}
+	return b.currentFrames[idx]
 }
 
 func (b *Battle) Draw(screen *ebiten.Image) {
+	// Pendant un tremblement, la scène est dessinée à part puis décalée
+	scene := screen
+	shakeX, shakeY := b.fx.shakeOffset()
+	if shakeX != 0 || shakeY != 0 {
+		w, h := screen.Size()
+		if b.canvas == nil || b.canvas.Bounds().Dx() != w || b.canvas.Bounds().Dy() != h {
+			b.canvas = ebiten.NewImage(w, h)
+		}
+		b.canvas.Clear()
+		scene = b.canvas
+	}
+
 	// Dessine le fond si disponible
 	if b.bg != nil {
-		screen.DrawImage(b.bg, &ebiten.DrawImageOptions{})
+		scene.DrawImage(b.bg, &ebiten.DrawImageOptions{})
 	}
 
 	// Récupération des dimensions de l'écran
@@ -321,133 +664,170 @@ func (b *Battle) Draw(screen *ebiten.Image) {
 	enemyX := float64(screenW/2) + 150
 	// Position Y du sol
 	groundY := float64(screenH - 400)
-
-	// Dessin des animations en cours
-	if b.animPlaying && len(b.currentFrames) > 0 {
-		idx := b.currentIndex
-		// S'assure qu'on ne dépasse pas le nombre de frames
-		if idx >= len(b.currentFrames) {
-			idx = len(b.currentFrames) - 1
-		}
-		frame := b.currentFrames[idx]
-
-		// Dessin selon l'attaquant
-		switch b.attacker {
-		case "player":
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Scale(scale, scale)
-			op.GeoM.Translate(playerX, groundY)
-			screen.DrawImage(frame, op)
-			// Dessine l'ennemi qui prend un coup si encore vivant
-			if b.enemyEgo > 0 && len(b.enemyHit) > 0 {
-				hitFrame := b.enemyHit[idx%len(b.enemyHit)]
-				op2 := &ebiten.DrawImageOptions{}
-				op2.GeoM.Scale(scale, scale)
-				op2.GeoM.Translate(enemyX, groundY)
-				screen.DrawImage(hitFrame, op2)
-			}
-		case "enemy":
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Scale(scale, scale)
-			op.GeoM.Translate(enemyX, groundY)
-			screen.DrawImage(frame, op)
-			// Dessine le joueur qui prend un coup si encore vivant
-			if b.playerEgo > 0 && len(b.playerHit) > 0 {
-				hitFrame := b.playerHit[idx%len(b.playerHit)]
-				op2 := &ebiten.DrawImageOptions{}
-				op2.GeoM.Scale(scale, scale)
-				op2.GeoM.Translate(playerX, groundY)
-				screen.DrawImage(hitFrame, op2)
-			}
-		case "dead_player":
-			// Animation de mort du joueur
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Scale(scale, scale)
-			op.GeoM.Translate(playerX, groundY)
-			screen.DrawImage(frame, op)
-			// Ennemi reste idle
-			op2 := &ebiten.DrawImageOptions{}
-			op2.GeoM.Scale(scale, scale)
-			op2.GeoM.Translate(enemyX, groundY)
-			screen.DrawImage(b.enemyIdle, op2)
-		case "dead_enemy":
-			// Animation de mort de l'ennemi
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Scale(scale, scale)
-			op.GeoM.Translate(enemyX, groundY)
-			screen.DrawImage(frame, op)
-			// Joueur reste idle
-			op2 := &ebiten.DrawImageOptions{}
-			op2.GeoM.Scale(scale, scale)
-			op2.GeoM.Translate(playerX, groundY)
-			screen.DrawImage(b.playerIdle, op2)
+	playerFlash := b.fx.flashing(SidePlayer)
+	enemyFlash := b.fx.flashing(SideEnemy)
+
+	// Dessin des personnages selon l'étape du combat
+	switch b.state {
+	case BattlePlayerAnim:
+		drawSprite(scene, b.currentFrame(), playerX, groundY, scale, playerFlash)
+		// Dessine l'ennemi qui prend un coup si encore vivant (idle si le joueur se met en garde ou respire)
+		if !moveDefs()[b.lastMove[SidePlayer]].Hits() {
+			drawSprite(scene, b.enemyIdle, enemyX, groundY, scale, enemyFlash)
+		} else if b.combat.Fighter(SideEnemy).Ego > 0 && len(b.enemyHit) > 0 {
+			drawSprite(scene, b.enemyHit[b.currentIndex%len(b.enemyHit)], enemyX, groundY, scale, enemyFlash)
 		}
-	} else if b.deadFinished && b.deadFrame != nil {
-		// Dessin de la frame finale si le combat est terminé
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Scale(scale, scale)
-		if b.attacker == "dead_enemy" {
-			op.GeoM.Translate(enemyX, groundY)
-			screen.DrawImage(b.deadFrame, op)
-			op2 := &ebiten.DrawImageOptions{}
-			op2.GeoM.Scale(scale, scale)
-			op2.GeoM.Translate(playerX, groundY)
-			screen.DrawImage(b.playerIdle, op2)
-		} else {
-			op.GeoM.Translate(playerX, groundY)
-			screen.DrawImage(b.deadFrame, op)
-			op2 := &ebiten.DrawImageOptions{}
-			op2.GeoM.Scale(scale, scale)
-			op2.GeoM.Translate(enemyX, groundY)
-			screen.DrawImage(b.enemyIdle, op2)
-		}
-
-		// Dessin de l'image de fin du combat si disponible
-		if b.endMsg != nil {
-			opMsg := &ebiten.DrawImageOptions{}
-			w, h := b.endMsg.Size()
-			endScale := 0.6
-			opMsg.GeoM.Scale(endScale, endScale)
-			opMsg.GeoM.Translate(
-				float64(screenW/2)-(float64(w)*endScale)/2,
-				float64(screenH/2)-(float64(h)*endScale)/2,
-			)
-			screen.DrawImage(b.endMsg, opMsg)
+	case BattleEnemyAnim:
+		drawSprite(scene, b.currentFrame(), enemyX, groundY, scale, enemyFlash)
+		// Dessine le joueur qui prend un coup si encore vivant (idle si l'ennemi se met en garde ou respire)
+		if !moveDefs()[b.lastMove[SideEnemy]].Hits() {
+			drawSprite(scene, b.playerIdle, playerX, groundY, scale, playerFlash)
+		} else if b.combat.Fighter(SidePlayer).Ego > 0 && len(b.playerHit) > 0 {
+			drawSprite(scene, b.playerHit[b.currentIndex%len(b.playerHit)], playerX, groundY, scale, playerFlash)
 		}
-	} else {
+	case BattleVictory:
+		// Animation de mort de l'ennemi, joueur idle
+		drawSprite(scene, b.currentFrame(), enemyX, groundY, scale, enemyFlash)
+		drawSprite(scene, b.playerIdle, playerX, groundY, scale, playerFlash)
+	case BattleDefeat:
+		// Animation de mort du joueur, ennemi idle
+		drawSprite(scene, b.currentFrame(), playerX, groundY, scale, playerFlash)
+		drawSprite(scene, b.enemyIdle, enemyX, groundY, scale, enemyFlash)
+	default:
 		// Dessin idle des deux personnages si pas d'animation
+		drawSprite(scene, b.playerIdle, playerX, groundY, scale, playerFlash)
+		drawSprite(scene, b.enemyIdle, enemyX, groundY, scale, enemyFlash)
+	}
+
+	if scene != screen {
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Scale(scale, scale)
-		op.GeoM.Translate(playerX, groundY)
-		screen.DrawImage(b.playerIdle, op)
-		op2 := &ebiten.DrawImageOptions{}
-		op2.GeoM.Scale(scale, scale)
-		op2.GeoM.Translate(enemyX, groundY)
-		screen.DrawImage(b.enemyIdle, op2)
+		op.GeoM.Translate(shakeX, shakeY)
+		screen.DrawImage(scene, op)
+	}
+
+	// Barres d'ego, ordre du tour, dégâts flottants et bannière d'attaque
+	b.fx.drawBars(screen, b.combat, b.fontSmall)
+	b.fx.drawTurnOrder(screen, b.combat, b.activeSide())
+	b.fx.drawOverlay(screen, b.fontBig, playerX, enemyX, groundY)
+
+	// Dessin de l'image de fin du combat si disponible
+	if b.deadFinished && b.endMsg != nil {
+		opMsg := &ebiten.DrawImageOptions{}
+		w, h := b.endMsg.Size()
+		endScale := 0.6
+		opMsg.GeoM.Scale(endScale, endScale)
+		opMsg.GeoM.Translate(
+			float64(screenW/2)-(float64(w)*endScale)/2,
+			float64(screenH/2)-(float64(h)*endScale)/2,
+		)
+		screen.DrawImage(b.endMsg, opMsg)
 	}
 
-	// Affiche les valeurs d'ego
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Votre égo: %d", b.playerEgo), 10, 10)
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("égo de Lil’ Patafix: %d", b.enemyEgo), screenW-155, 10)
+	// Indique que le combat affiché est un replay
+	if b.replay {
+		ebitenutil.DebugPrintAt(screen, "REPLAY - ESC pour quitter", screenW/2-75, 10)
+	}
 
 	// Affiche le dialogue en cours si encore actif
 	if b.currentLine != "" && time.Since(b.lineStart) < b.lineDuration {
-		x := float64((screenW - len(b.currentLine)*7) / 2)
-		y := float64(screenH/2 - 10)
-		ebitenutil.DebugPrintAt(screen, b.currentLine, int(x), int(y))
+		speakerX := playerX
+		if b.lineSide == SideEnemy {
+			speakerX = enemyX
+		}
+		drawSpeechBubble(screen, b.currentLine, b.fontSmall, speakerX+144, groundY-130)
+	}
+
+	// Combat interrompu : raison au centre de l'écran
+	if b.state == BattleAborted {
+		msg := b.abortMsg + " - Entrée pour quitter"
+		ebitenutil.DebugPrintAt(screen, msg, (screenW-len(msg)*6)/2, screenH/2+40)
+	}
+
+	// Freestyle en cours d'écriture
+	if b.state == BattleFreestyle {
+		b.drawFreestyle(screen)
+	}
+
+	// En réseau, on attend le choix de l'adversaire
+	if _, ok := b.controllers[b.chooser].(*NetRemoteController); ok && b.state == BattleChooseMove {
+		msg := "En attente de " + b.combat.Fighter(b.chooser).Name + "..."
+		ebitenutil.DebugPrintAt(screen, msg, (screenW-len(msg)*6)/2, screenH-80)
 	}
 
-	// Dessin du menu joueur si pas de combat en cours
-	if !b.deadFinished && b.attacker == "" {
-		for i, option := range b.menuOptions {
-			y := screenH - 60 + i*20
+	// Dessin du menu du camp qui choisit, s'il est joué au clavier
+	if mc, ok := b.controllers[b.chooser].(menuController); ok && b.state == BattleChooseMove {
+		h := mc.Menu()
+		moves := b.combat.Moves(b.chooser)
+		menuX := 10
+		if b.chooser == SideEnemy {
+			menuX = screenW - 380
+		}
+		menuY := screenH - 20 - len(moves)*20
+		for i, move := range moves {
+			y := menuY + i*20
 			prefix := "  "
-			if i == b.selectedOption {
+			if i == h.selected {
 				prefix = "> "
 			}
-			ebitenutil.DebugPrintAt(screen, prefix+option, 10, y)
+			option := moveDefs()[move].Name
+			if cost := moveDefs()[move].Cost; cost > 0 {
+				option += fmt.Sprintf(" [%d flow]", cost)
+			}
+			if cd := b.combat.Cooldown(b.chooser, move); cd > 0 {
+				option += fmt.Sprintf(" (recharge %d)", cd)
+			}
+			// Attaque impossible (pas assez de flow ou en recharge) : grisée
+			col := color.Color(color.White)
+			if !b.combat.CanUse(b.chooser, move) {
+				col = color.RGBA{110, 110, 110, 255}
+			}
+			text.Draw(screen, prefix+option, basicfont.Face7x13, menuX, y+12, col)
+		}
+		// Passif de classe et rappel des touches au-dessus du menu
+		header := menuY - 20
+		if class, ok := ClassFor(b.combat.Fighter(b.chooser).Class); ok {
+			ebitenutil.DebugPrintAt(screen, "Passif : "+class.Passive.Name+" ("+class.Passive.Desc+")", menuX, header)
+			header -= 20
 		}
+		if b.versus {
+			hint := fmt.Sprintf("%s : %s", b.combat.Fighter(b.chooser).Name, h.Keys.Hint)
+			ebitenutil.DebugPrintAt(screen, hint, menuX, header)
+		}
+	}
+}
+
+// drawFreestyle dessine la zone d'écriture du freestyle : phrase à laquelle répondre, texte tapé et temps restant
+func (b *Battle) drawFreestyle(screen *ebiten.Image) {
+	screenW, _ := screen.Size()
+	face := b.fontSmall
+	if face == nil {
+		face = basicfont.Face7x13
+	}
+	w, h := 900, 220
+	x, y := (screenW-w)/2, 210
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h), color.RGBA{10, 10, 30, 230}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 3, color.RGBA{255, 215, 0, 255}, false)
+
+	name := b.combat.Fighter(b.freestyleSide).Name
+	text.Draw(screen, "FREESTYLE de "+name+" - rime avec :", face, x+20, y+35, color.RGBA{255, 215, 0, 255})
+	text.Draw(screen, "« "+b.freestylePrompt+" »", basicfont.Face7x13, x+20, y+70, color.RGBA{200, 200, 200, 255})
+
+	// Texte tapé, avec un curseur qui clignote
+	cursor := ""
+	if b.freestyleTicks/20%2 == 0 {
+		cursor = "_"
 	}
+	text.Draw(screen, "> "+b.freestyleText+cursor, basicfont.Face7x13, x+20, y+115, color.White)
+
+	// Syllabes comptées en direct
+	score := ScoreBar(b.freestyleText, b.freestylePrompt)
+	info := fmt.Sprintf("Syllabes : %d / %d   -   Entrée pour rapper", score.Syllables, freestyleSyllables)
+	text.Draw(screen, info, basicfont.Face7x13, x+20, y+155, color.RGBA{160, 160, 160, 255})
+
+	// Temps restant
+	left := 1 - float32(b.freestyleTicks)/float32(freestyleSeconds*60)
+	vector.DrawFilledRect(screen, float32(x+20), float32(y+185), float32(w-40)*left, 12, color.RGBA{255, 90, 60, 255}, false)
+	vector.StrokeRect(screen, float32(x+20), float32(y+185), float32(w-40), 12, 2, color.White, false)
 }
 
 // Fonction qui retourne si le combat est terminé
@@ -0,0 +1,162 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+// Nombre maximum de ticks d'un combat de test (les animations durent quelques dizaines de ticks)
+const battleTestTicks = 5000
+
+// scriptedController joue une liste d'attaques fixée d'avance, après avoir fait attendre le combat wait ticks
+type scriptedController struct {
+	moves []int
+	wait  int
+	calls int // Appels de ChooseMove
+}
+
+func (s *scriptedController) ChooseMove(b *Battle, side Side) (int, bool) {
+	s.calls++
+	if s.wait > 0 {
+		s.wait--
+		return 0, false
+	}
+	if len(s.moves) == 0 {
+		return MoveBreathe, true
+	}
+	move := s.moves[0]
+	s.moves = s.moves[1:]
+	return move, true
+}
+
+// newScriptedBattle crée un combat entre deux combattants joués par des scripts
+func newScriptedBattle(t *testing.T, player, enemy Fighter, p, e BattleController) *Battle {
+	t.Helper()
+	b := newBattleScene(NewCombat(1, player, enemy))
+	b.controllers = [2]BattleController{p, e}
+	return b
+}
+
+// runBattle fait avancer le combat sans entrée jusqu'à la fin et retourne les étapes traversées
+func runBattle(t *testing.T, b *Battle) []BattleState {
+	t.Helper()
+	states := []BattleState{b.State()}
+	for range battleTestTicks {
+		b.advance(battleInput{})
+		if st := b.State(); st != states[len(states)-1] {
+			states = append(states, st)
+		}
+		if st := b.State(); (st == BattleVictory || st == BattleDefeat) && b.deadFinished {
+			return states
+		}
+	}
+	t.Fatalf("combat non terminé, étapes : %v", states)
+	return nil
+}
+
+func TestBattleVictory(t *testing.T) {
+	t.Chdir("..")
+	// Le joueur est le plus rapide : sa punchline met l'ennemi KO avant qu'il joue
+	b := newScriptedBattle(t,
+		Fighter{Name: "Joueur", Ego: 100, Flow: 10, MaxFlow: 10},
+		Fighter{Name: "Ennemi", Ego: 5, Flow: 5, MaxFlow: 5},
+		&scriptedController{moves: []int{MovePunchline}}, &scriptedController{})
+
+	want := []BattleState{BattleChooseMove, BattlePlayerAnim, BattleResolve, BattleVictory}
+	if got := runBattle(t, b); !slices.Equal(got, want) {
+		t.Errorf("étapes %v, attendu %v", got, want)
+	}
+	if b.Winner != SidePlayer.String() {
+		t.Errorf("gagnant %q", b.Winner)
+	}
+
+	// L'écran de fin attend Entrée
+	b.advance(battleInput{})
+	if b.IsOver() {
+		t.Fatal("combat fermé sans Entrée")
+	}
+	b.advance(battleInput{Confirm: true})
+	if !b.IsOver() {
+		t.Error("Entrée ne ferme pas le combat")
+	}
+}
+
+func TestBattleDefeat(t *testing.T) {
+	t.Chdir("..")
+	// L'ennemi est le plus rapide et met le joueur KO
+	b := newScriptedBattle(t,
+		Fighter{Name: "Joueur", Ego: 5, Flow: 5, MaxFlow: 5},
+		Fighter{Name: "Ennemi", Ego: 100, Flow: 10, MaxFlow: 10},
+		&scriptedController{}, &scriptedController{moves: []int{MovePunchline}})
+
+	want := []BattleState{BattleChooseMove, BattleEnemyAnim, BattleResolve, BattleDefeat}
+	if got := runBattle(t, b); !slices.Equal(got, want) {
+		t.Errorf("étapes %v, attendu %v", got, want)
+	}
+	if b.Winner != SideEnemy.String() {
+		t.Errorf("gagnant %q", b.Winner)
+	}
+}
+
+func TestBattleFullTurn(t *testing.T) {
+	t.Chdir("..")
+	// Le joueur réfléchit quelques ticks, l'ennemi répond, puis le tour suivant commence
+	player := &scriptedController{moves: []int{MoveFlow}, wait: 3}
+	enemy := &scriptedController{moves: []int{MoveFlow}}
+	b := newScriptedBattle(t,
+		Fighter{Name: "Joueur", Ego: 100, Flow: 10, MaxFlow: 10},
+		Fighter{Name: "Ennemi", Ego: 100, Flow: 5, MaxFlow: 5},
+		player, enemy)
+
+	states := []BattleState{b.State()}
+	for range battleTestTicks {
+		b.advance(battleInput{})
+		if st := b.State(); st != states[len(states)-1] {
+			states = append(states, st)
+		}
+		if b.combat.Turn == 2 {
+			break
+		}
+	}
+	want := []BattleState{BattleChooseMove, BattlePlayerAnim, BattleResolve, BattleChooseMove, BattleEnemyAnim, BattleResolve, BattleChooseMove}
+	if !slices.Equal(states, want) {
+		t.Errorf("étapes %v, attendu %v", states, want)
+	}
+	if player.calls != 4 || enemy.calls != 1 {
+		t.Errorf("appels de ChooseMove : joueur %d, ennemi %d", player.calls, enemy.calls)
+	}
+	if b.State() != BattleChooseMove || b.chooser != b.combat.Order()[0] {
+		t.Errorf("tour 2 : étape %v, camp %v au lieu de %v", b.State(), b.chooser, b.combat.Order()[0])
+	}
+}
+
+func TestBattleReflectKO(t *testing.T) {
+	t.Chdir("..")
+	// L'ennemi se met en garde, le joueur tape dans le renvoi et tombe avec sa propre punchline
+	b := newScriptedBattle(t,
+		Fighter{Name: "Joueur", Ego: 5, Flow: 5, MaxFlow: 5},
+		Fighter{Name: "Ennemi", Ego: 100, Flow: 10, MaxFlow: 10},
+		&scriptedController{moves: []int{MovePunchline}}, &scriptedController{moves: []int{MoveCounter}})
+
+	want := []BattleState{
+		BattleChooseMove, BattleEnemyAnim, BattleResolve,
+		BattleChooseMove, BattlePlayerAnim, BattleResolve, BattleDefeat,
+	}
+	if got := runBattle(t, b); !slices.Equal(got, want) {
+		t.Errorf("étapes %v, attendu %v", got, want)
+	}
+	if b.Winner != SideEnemy.String() || b.combat.Fighter(SideEnemy).Ego != 100 {
+		t.Errorf("gagnant %q, ego de l'ennemi %d", b.Winner, b.combat.Fighter(SideEnemy).Ego)
+	}
+}
+
+func TestMoveHits(t *testing.T) {
+	t.Chdir("..")
+	// Seules les attaques qui visent l'adversaire montrent son sprite touché
+	for move, def := range moveDefs() {
+		want := move != MoveBlock && move != MoveDodge && move != MoveCounter && move != MoveBreathe
+		if def.Hits() != want {
+			t.Errorf("%s : Hits() = %v", def.Name, def.Hits())
+		}
+	}
+}
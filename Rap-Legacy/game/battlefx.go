@@ -0,0 +1,288 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"fmt"         // Pour formater les dégâts
+	"image/color" // Pour les couleurs des barres et des textes
+	"math"        // Pour l'amplitude du tremblement
+
+	"github.com/hajimehoshi/ebiten/v2"        // Ebiten, moteur 2D
+	"github.com/hajimehoshi/ebiten/v2/text"   // Pour dessiner du texte avec une police
+	"github.com/hajimehoshi/ebiten/v2/vector" // Pour dessiner les barres d'ego
+	"golang.org/x/image/font"                 // Interface des polices
+	"golang.org/x/image/font/basicfont"       // Police de secours
+)
+
+// Durées des effets, en ticks (60 par seconde)
+const (
+	fxFloatTicks  = 60 // Durée de vie d'un nombre flottant
+	fxFlashTicks  = 12 // Durée du flash quand un combattant est touché
+	fxShakeTicks  = 18 // Durée du tremblement d'écran
+	fxBannerTicks = 70 // Durée d'affichage du nom de l'attaque
+)
+
+// Dégâts à partir desquels l'écran tremble (les critiques tremblent toujours)
+const fxHeavyHit = 20
+
+// floatingText est un nombre de dégâts qui monte au-dessus d'un combattant
+type floatingText struct {
+	text  string
+	side  Side
+	crit  bool
+	flow  bool // Flow regagné (affiché en bleu)
+	ticks int  // Ticks écoulés depuis l'apparition
+}
+
+// battleFX gère les effets visuels du combat, déclenchés par les événements du moteur
+type battleFX struct {
+	reducedMotion bool // Désactive tremblement, flash et animations de barres
+
+	shownEgo [2]float64 // Ego affiché par les barres (rattrape l'ego réel)
+	maxEgo   [2]int     // Ego maximum vu pendant le combat (taille des barres)
+
+	floats      []floatingText // Nombres flottants en cours
+	flash       [2]int         // Ticks restants de flash par camp
+	shake       int            // Ticks restants de tremblement
+	banner      string         // Nom de l'attaque en cours
+	bannerTicks int            // Ticks restants d'affichage de la bannière
+	cursor      int            // Nombre d'événements du journal déjà traités
+	orderTie    bool           // Ordre du tour courant tiré au sort ?
+}
+
+// newBattleFX initialise les barres à l'ego de départ
+func newBattleFX(c *Combat) *battleFX {
+	fx := &battleFX{}
+	for s := range c.Fighters {
+		fx.shownEgo[s] = float64(c.Fighters[s].Ego)
+		fx.maxEgo[s] = c.Fighters[s].Ego
+	}
+	return fx
+}
+
+// update traite les nouveaux événements du combat puis fait vieillir les effets
+func (fx *battleFX) update(c *Combat) {
+	for ; fx.cursor < len(c.Log); fx.cursor++ {
+		fx.handle(c, c.Log[fx.cursor])
+	}
+
+	// Les barres rattrapent l'ego réel (instantané en mouvements réduits)
+	for s := range c.Fighters {
+		target := float64(c.Fighters[s].Ego)
+		if c.Fighters[s].Ego > fx.maxEgo[s] {
+			fx.maxEgo[s] = c.Fighters[s].Ego
+		}
+		if fx.reducedMotion || math.Abs(fx.shownEgo[s]-target) < 0.5 {
+			fx.shownEgo[s] = target
+		} else {
+			fx.shownEgo[s] += (target - fx.shownEgo[s]) * 0.12
+		}
+		if fx.flash[s] > 0 {
+			fx.flash[s]--
+		}
+	}
+
+	active := fx.floats[:0]
+	for _, f := range fx.floats {
+		f.ticks++
+		if f.ticks < fxFloatTicks {
+			active = append(active, f)
+		}
+	}
+	fx.floats = active
+
+	if fx.shake > 0 {
+		fx.shake--
+	}
+	if fx.bannerTicks > 0 {
+		fx.bannerTicks--
+	}
+}
+
+// handle déclenche les effets correspondant à un événement
+func (fx *battleFX) handle(c *Combat, ev BattleEvent) {
+	switch ev.Type {
+	case EventMoveChosen:
+		fx.banner = fmt.Sprintf("%s : %s", c.Fighters[ev.Actor].Name, moveDefs()[ev.Move].Name)
+		if moveDefs()[ev.Move].Kind == KindDefense {
+			fx.banner = c.Fighters[ev.Actor].Name + " se met en garde..." // La défense reste secrète
+		}
+		fx.bannerTicks = fxBannerTicks
+	case EventDamage:
+		if ev.Amount == 0 {
+			return // Coup paré : le texte de la défense suffit
+		}
+		label := fmt.Sprintf("-%d", ev.Amount)
+		if ev.Crit {
+			label = fmt.Sprintf("CRITIQUE ! -%d", ev.Amount)
+		}
+		fx.floats = append(fx.floats, floatingText{text: label, side: ev.Target, crit: ev.Crit})
+		if !fx.reducedMotion {
+			fx.flash[ev.Target] = fxFlashTicks
+			if ev.Crit || ev.Amount >= fxHeavyHit {
+				fx.shake = fxShakeTicks
+			}
+		}
+	case EventStatus, EventHeal:
+		label := fmt.Sprintf("%+d", ev.Amount)
+		fx.floats = append(fx.floats, floatingText{text: label, side: ev.Target})
+	case EventInitiative:
+		fx.floats = append(fx.floats, floatingText{text: fmt.Sprintf("%+d vitesse", ev.Amount), side: ev.Target, flow: true})
+	case EventOrder:
+		fx.orderTie = ev.Crit
+	case EventFreestyle:
+		fx.banner = fmt.Sprintf("%s : Freestyle noté %d/100", c.Fighters[ev.Actor].Name, ev.Amount)
+		fx.bannerTicks = fxBannerTicks
+	case EventFlow:
+		fx.floats = append(fx.floats, floatingText{text: fmt.Sprintf("+%d flow", ev.Amount), side: ev.Target, flow: true})
+	case EventGuard:
+		label := guardLabels[ev.Status] + " (" + moveDefs()[ev.Move].Name + ")"
+		fx.floats = append(fx.floats, floatingText{text: label, side: ev.Actor, crit: ev.Status != "faille"})
+	}
+}
+
+// Textes affichés quand une défense joue
+var guardLabels = map[string]string{
+	"parade": "PARÉ !",
+	"renvoi": "RENVOYÉE !",
+	"faille": "FAILLE !",
+}
+
+// shakeOffset retourne le décalage de l'écran pendant un tremblement
+func (fx *battleFX) shakeOffset() (float64, float64) {
+	if fx.shake == 0 {
+		return 0, 0
+	}
+	amp := 12 * float64(fx.shake) / fxShakeTicks
+	return amp * math.Sin(float64(fx.shake)*2.1), amp * math.Cos(float64(fx.shake)*1.7)
+}
+
+// flashing indique si le sprite d'un camp doit être teinté ce tick-ci
+func (fx *battleFX) flashing(s Side) bool {
+	return fx.flash[s] > 0 && (fx.flash[s]/3)%2 == 0
+}
+
+// drawBars dessine les barres d'ego des deux combattants
+func (fx *battleFX) drawBars(screen *ebiten.Image, c *Combat, face font.Face) {
+	if face == nil {
+		face = basicfont.Face7x13
+	}
+	screenW, _ := screen.Size()
+	barW, barH := float32(500), float32(28)
+	xs := [2]float32{300, float32(screenW) - 300 - barW} // À droite du HUD argent / followers
+
+	for s := range c.Fighters {
+		x, y := xs[s], float32(50)
+		ratio := float32(0)
+		if fx.maxEgo[s] > 0 {
+			ratio = float32(fx.shownEgo[s]) / float32(fx.maxEgo[s])
+		}
+		ratio = max(0, min(1, ratio))
+
+		// Couleur selon l'ego restant
+		fill := color.RGBA{60, 200, 90, 255}
+		if ratio < 0.5 {
+			fill = color.RGBA{230, 180, 40, 255}
+		}
+		if ratio < 0.25 {
+			fill = color.RGBA{220, 50, 50, 255}
+		}
+
+		vector.DrawFilledRect(screen, x, y, barW, barH, color.RGBA{20, 20, 20, 200}, false)
+		vector.DrawFilledRect(screen, x, y, barW*ratio, barH, fill, false)
+		vector.StrokeRect(screen, x, y, barW, barH, 3, color.White, false)
+
+		label := fmt.Sprintf("%s - ego %d", c.Fighters[s].Name, max(0, c.Fighters[s].Ego))
+		text.Draw(screen, label, face, int(x), int(y)-10, color.White)
+
+		// Jauge de flow sous la barre d'ego
+		f := c.Fighters[s]
+		flowY := y + barH + 6
+		flowRatio := float32(0)
+		if f.MaxFlow > 0 {
+			flowRatio = max(0, min(1, float32(f.Flow)/float32(f.MaxFlow)))
+		}
+		vector.DrawFilledRect(screen, x, flowY, barW, 10, color.RGBA{20, 20, 20, 200}, false)
+		vector.DrawFilledRect(screen, x, flowY, barW*flowRatio, 10, color.RGBA{70, 140, 255, 255}, false)
+		text.Draw(screen, fmt.Sprintf("flow %d/%d", f.Flow, f.MaxFlow), face, int(x+barW)+10, int(flowY)+10, color.RGBA{90, 170, 255, 255})
+
+		// Défense active sous la barre (sans dire laquelle)
+		if c.Guard(Side(s)) != noGuard {
+			text.Draw(screen, "En garde", face, int(x), int(flowY)+35, color.RGBA{120, 200, 255, 255})
+		}
+	}
+}
+
+// drawTurnOrder dessine la frise de l'ordre du tour entre les deux barres d'ego.
+// current est le camp qui agit ; la vitesse prévue pour le tour suivant est indiquée si elle change.
+func (fx *battleFX) drawTurnOrder(screen *ebiten.Image, c *Combat, current Side) {
+	face := basicfont.Face7x13
+	screenW, _ := screen.Size()
+	boxW, boxH := float32(140), float32(36)
+	x0 := float32(screenW)/2 - boxW - 10
+	y := float32(40)
+
+	text.Draw(screen, fmt.Sprintf("Tour %d", c.Turn), face, int(x0), int(y)-8, color.White)
+	for i, s := range c.Order() {
+		x := x0 + float32(i)*(boxW+20)
+		border := color.Color(color.RGBA{150, 150, 150, 255})
+		if s == current {
+			border = color.RGBA{255, 215, 0, 255}
+		}
+		vector.DrawFilledRect(screen, x, y, boxW, boxH, color.RGBA{20, 20, 20, 200}, false)
+		vector.StrokeRect(screen, x, y, boxW, boxH, 2, border, false)
+
+		name := []rune(c.Fighters[s].Name)
+		if len(name) > 18 {
+			name = append(name[:17], '.')
+		}
+		text.Draw(screen, string(name), face, int(x)+6, int(y)+15, color.White)
+		speed := fmt.Sprintf("vitesse %d", c.Fighters[s].MaxFlow)
+		if next := c.Speed(s); next != c.Fighters[s].MaxFlow {
+			speed += fmt.Sprintf(" > %d", next)
+		}
+		text.Draw(screen, speed, face, int(x)+6, int(y)+30, color.RGBA{90, 170, 255, 255})
+		if i == 0 {
+			text.Draw(screen, ">", face, int(x+boxW)+7, int(y)+22, color.White)
+		}
+	}
+	if fx.orderTie {
+		text.Draw(screen, "Égalité : ordre tiré au sort", face, int(x0), int(y+boxH)+16, color.RGBA{200, 200, 200, 255})
+	}
+}
+
+// drawOverlay dessine les nombres flottants et la bannière d'attaque
+func (fx *battleFX) drawOverlay(screen *ebiten.Image, face font.Face, playerX, enemyX, groundY float64) {
+	if face == nil {
+		face = basicfont.Face7x13
+	}
+	screenW, _ := screen.Size()
+
+	for i, f := range fx.floats {
+		x := playerX + 120
+		if f.side == SideEnemy {
+			x = enemyX + 120
+		}
+		y := groundY - 40 - float64(i*40)
+		if !fx.reducedMotion {
+			y -= float64(f.ticks) * 1.5 // Le nombre monte en s'effaçant
+		}
+		alpha := uint8(255 - 200*f.ticks/fxFloatTicks)
+		col := color.NRGBA{255, 255, 255, alpha}
+		if f.crit {
+			col = color.NRGBA{255, 200, 0, alpha}
+		}
+		if f.flow {
+			col = color.NRGBA{90, 170, 255, alpha}
+		}
+		text.Draw(screen, f.text, face, int(x), int(y), col)
+	}
+
+	if fx.bannerTicks > 0 {
+		w := text.BoundString(face, fx.banner).Dx()
+		x := (screenW - w) / 2
+		if !fx.reducedMotion && fx.bannerTicks > fxBannerTicks-10 {
+			x -= (fx.bannerTicks - (fxBannerTicks - 10)) * 40 // Glisse depuis la gauche
+		}
+		vector.DrawFilledRect(screen, 0, 130, float32(screenW), 60, color.RGBA{0, 0, 0, 160}, false)
+		text.Draw(screen, fx.banner, face, x, 172, color.RGBA{255, 255, 0, 255})
+	}
+}
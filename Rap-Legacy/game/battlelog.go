@@ -0,0 +1,83 @@
+package game // Déclare le package "game", utilisé pour organiser le code
+
+import (
+	"encoding/json" // Pour encoder et décoder le journal en JSON
+	"os"            // Pour lire/écrire les fichiers
+	"path/filepath" // Pour construire le chemin du journal
+	"time"          // Pour horodater les combats
+)
+
+// Nombre de combats conservés par sauvegarde
+const maxBattleRecords = 10
+
+// BattleRecord contient tout ce qu'il faut pour rejouer un combat
+type BattleRecord struct {
+	Created int64         `json:"created_unix"` // Timestamp Unix de fin du combat
+	Seed    uint64        `json:"seed"`         // Seed du générateur du combat
+	Player  Fighter       `json:"player"`       // Joueur au début du combat (avant statuts)
+	Enemy   Fighter       `json:"enemy"`        // Ennemi au début du combat (avant statuts)
+	Winner  string        `json:"winner"`       // "player" ou "enemy"
+	Events  []BattleEvent `json:"events"`       // Journal complet des événements
+}
+
+// NewBattleRecord construit l'enregistrement d'un combat terminé
+func NewBattleRecord(b *Battle) BattleRecord {
+	return BattleRecord{
+		Created: time.Now().Unix(),
+		Seed:    b.combat.Seed,
+		Player:  b.startFighters[SidePlayer],
+		Enemy:   b.startFighters[SideEnemy],
+		Winner:  b.Winner,
+		Events:  b.combat.Log,
+	}
+}
+
+// Retourne le chemin du journal des combats d'une sauvegarde (à côté de saves.json)
+func battleLogPath(saveName string) string {
+	return filepath.Join(savesDir, saveName+"_battles.json")
+}
+
+// LoadBattleRecords charge les derniers combats d'une sauvegarde (du plus ancien au plus récent)
+func LoadBattleRecords(saveName string) ([]BattleRecord, error) {
+	data, err := os.ReadFile(battleLogPath(saveName))
+	if os.IsNotExist(err) { // Pas encore de combat enregistré
+		return []BattleRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []BattleRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return []BattleRecord{}, err
+	}
+	return records, nil
+}
+
+// AppendBattleRecord ajoute un combat au journal en ne gardant que les maxBattleRecords derniers
+func AppendBattleRecord(saveName string, rec BattleRecord) error {
+	if err := ensureSavesPath(); err != nil {
+		return err
+	}
+	records, err := LoadBattleRecords(saveName)
+	if err != nil {
+		records = []BattleRecord{} // Journal illisible : on repart de zéro
+	}
+	records = append(records, rec)
+	if len(records) > maxBattleRecords {
+		records = records[len(records)-maxBattleRecords:]
+	}
+	d, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(battleLogPath(saveName), d, 0o644)
+}
+
+// DeleteBattleRecords supprime le journal des combats d'une sauvegarde
+func DeleteBattleRecords(saveName string) error {
+	err := os.Remove(battleLogPath(saveName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
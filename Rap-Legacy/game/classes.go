@@ -0,0 +1,155 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"encoding/json" // Les classes et les attaques sont décrites en JSON
+	"fmt"           // Erreurs de lecture
+	"log"           // Erreurs de chargement
+	"os"            // Lecture du fichier
+	"sync"          // Chargement unique des données
+)
+
+// -----------------
+// Classes : passifs et attaques signature
+// -----------------
+
+// Fichier des attaques et des classes
+const classesPath = "assets/classes.json"
+
+// MoveBonus donne un bonus par attaque (index dans moveDefs) ; les fichiers utilisent l'identifiant de l'attaque
+type MoveBonus map[int]int
+
+// UnmarshalJSON lit un bonus indexé par identifiant d'attaque ("punchline": 15)
+func (b *MoveBonus) UnmarshalJSON(data []byte) error {
+	var raw map[string]int
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*b = MoveBonus{}
+	for key, v := range raw {
+		move, ok := moveIndex(key)
+		if !ok {
+			return fmt.Errorf("attaque inconnue : %q", key)
+		}
+		(*b)[move] = v
+	}
+	return nil
+}
+
+// UnmarshalJSON lit une famille d'attaques par son nom (voir kindKeys)
+func (k *MoveKind) UnmarshalJSON(data []byte) error {
+	var key string
+	if err := json.Unmarshal(data, &key); err != nil {
+		return err
+	}
+	for kind, name := range kindKeys {
+		if name == key {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("famille d'attaque inconnue : %q", key)
+}
+
+// ClassPassive est un bonus permanent propre à une classe
+type ClassPassive struct {
+	Name          string    `json:"name"`                     // Nom affiché
+	Desc          string    `json:"desc"`                     // Description courte (création de save, menu de combat)
+	CritBonus     MoveBonus `json:"crit_bonus,omitempty"`     // Chance de critique en plus (en %) par attaque
+	DamageBonus   MoveBonus `json:"damage_bonus,omitempty"`   // Dégâts en plus par attaque
+	FollowerBonus int       `json:"follower_bonus,omitempty"` // Followers en plus après une victoire (en %)
+}
+
+// ClassDef décrit une classe jouable
+type ClassDef struct {
+	Passive   ClassPassive // Bonus permanent
+	Signature int          // Attaque signature (index dans moveDefs, répliques dans assets/dialogue.json)
+}
+
+// ClassRegistry contient les attaques et les classes
+type ClassRegistry struct {
+	moves   []MoveDef           // Attaques, indexées par Move...
+	classes map[string]ClassDef // Classes, indexées par le nom enregistré dans la save
+}
+
+// LoadClassRegistry charge les attaques et les classes. Toutes les attaques doivent être décrites : sans registre
+// complet, aucun combat n'a de sens, donc le registre n'est retourné que si le fichier est entièrement valide.
+func LoadClassRegistry(path string) (*ClassRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Moves   []MoveDef `json:"moves"`
+		Classes []struct {
+			Name      string       `json:"name"`      // Nom enregistré dans la save
+			Signature string       `json:"signature"` // Identifiant de l'attaque signature
+			Passive   ClassPassive `json:"passive"`
+		} `json:"classes"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	r := &ClassRegistry{moves: make([]MoveDef, len(moveKeys)), classes: map[string]ClassDef{}}
+	found := make([]bool, len(moveKeys))
+	for _, m := range file.Moves {
+		i, ok := moveIndex(m.Key)
+		if !ok {
+			return nil, fmt.Errorf("attaque inconnue : %q", m.Key)
+		}
+		if found[i] {
+			return nil, fmt.Errorf("attaque décrite deux fois : %q", m.Key)
+		}
+		r.moves[i], found[i] = m, true
+	}
+	for i, ok := range found {
+		if !ok {
+			return nil, fmt.Errorf("attaque absente : %q", moveKeys[i])
+		}
+	}
+	for _, c := range file.Classes {
+		signature, ok := moveIndex(c.Signature)
+		if !ok {
+			return nil, fmt.Errorf("signature inconnue pour %s : %q", c.Name, c.Signature)
+		}
+		r.classes[c.Name] = ClassDef{Passive: c.Passive, Signature: signature}
+	}
+	return r, nil
+}
+
+var (
+	classesOnce   sync.Once
+	classRegistry *ClassRegistry
+	classesErr    error
+)
+
+// LoadClasses charge les attaques et les classes depuis assets/classes.json. À appeler au lancement : le jeu ne peut
+// pas démarrer sans elles.
+func LoadClasses() error {
+	classesOnce.Do(func() {
+		classRegistry, classesErr = LoadClassRegistry(classesPath)
+		if classesErr != nil {
+			classesErr = fmt.Errorf("%s : %w", classesPath, classesErr)
+		}
+	})
+	return classesErr
+}
+
+// loadedClasses retourne les attaques et les classes, chargées au premier appel (arrêt du jeu si le fichier est
+// invalide)
+func loadedClasses() *ClassRegistry {
+	if err := LoadClasses(); err != nil {
+		log.Fatal("Impossible de charger les classes : ", err)
+	}
+	return classRegistry
+}
+
+// moveDefs retourne toutes les attaques (identiques pour les deux camps), indexées par Move...
+func moveDefs() []MoveDef {
+	return loadedClasses().moves
+}
+
+// ClassFor retourne la définition d'une classe (false pour un ennemi ou une classe inconnue)
+func ClassFor(class string) (ClassDef, bool) {
+	def, ok := loadedClasses().classes[class]
+	return def, ok
+}
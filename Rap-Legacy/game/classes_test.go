@@ -0,0 +1,59 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadClassRegistry(t *testing.T) {
+	r, err := LoadClassRegistry(filepath.Join("..", classesPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.moves) != len(moveKeys) {
+		t.Fatalf("%d attaques, %d attendues", len(r.moves), len(moveKeys))
+	}
+	for i, m := range r.moves {
+		if m.Key != moveKeys[i] || m.Name == "" || m.Name == m.Key {
+			t.Errorf("attaque %d mal chargée : %+v", i, m)
+		}
+	}
+	if r.moves[MovePunchline].Damage <= 0 {
+		t.Error("la punchline ne fait pas de dégâts")
+	}
+	if def, ok := r.classes["Lyricistes"]; !ok || def.Signature != MoveMultiRhymes {
+		t.Errorf("Lyricistes : %+v", def)
+	}
+}
+
+func TestLoadClassRegistryErrors(t *testing.T) {
+	valid, err := os.ReadFile(filepath.Join("..", classesPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name string
+		data string // "" : fichier absent
+	}{
+		{"fichier absent", ""},
+		{"JSON invalide", `{"moves": [`},
+		{"attaque inconnue", strings.Replace(string(valid), `"key": "flow"`, `"key": "flowz"`, 1)},
+		{"attaque absente", strings.Replace(string(valid), `"key": "breathe"`, `"key": "punchline"`, 1)},
+		{"signature inconnue", strings.Replace(string(valid), `"signature": "stage_show"`, `"signature": "show"`, 1)},
+	} {
+		path := filepath.Join(dir, "absent.json")
+		if tc.data != "" {
+			path = filepath.Join(dir, "classes.json")
+			if err := os.WriteFile(path, []byte(tc.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r, err := LoadClassRegistry(path)
+		if err == nil || r != nil {
+			t.Errorf("%s : registre %v, erreur %v ; erreur sans registre attendue", tc.name, r, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import "fmt" // Affichage de l'heure
+
+// -----------------
+// Horloge du jeu
+// -----------------
+
+// Durée d'une heure de jeu en ticks (60 par seconde) : une journée dure 10 minutes
+const (
+	hourTicks = 25 * 60
+	dayTicks  = 24 * hourTicks
+)
+
+// GameClock est le temps passé sur la map depuis le début de la partie, en ticks (enregistré dans la save)
+type GameClock int64
+
+// Day retourne le numéro du jour (à partir de 1)
+func (c GameClock) Day() int {
+	return int(c/dayTicks) + 1
+}
+
+// Hour retourne l'heure de la journée (0 à 23)
+func (c GameClock) Hour() int {
+	return int(c % dayTicks / hourTicks)
+}
+
+// String retourne l'heure affichée ("Jour 3 - 14h")
+func (c GameClock) String() string {
+	return fmt.Sprintf("Jour %d - %dh", c.Day(), c.Hour())
+}
+
+// HoursUntil retourne le nombre d'heures de jeu entamées avant t (0 si t est passé)
+func (c GameClock) HoursUntil(t GameClock) int {
+	if t <= c {
+		return 0
+	}
+	return int((t - c + hourTicks - 1) / hourTicks)
+}
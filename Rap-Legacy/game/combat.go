@@ -0,0 +1,518 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"encoding/binary"    // Pour construire l'empreinte de l'état
+	"hash/fnv"           // Hash rapide pour comparer deux états de combat
+	mrand "math/rand/v2" // Générateur PCG copiable et reproductible à partir d'une seed
+)
+
+// -----------------
+// Camps et attaques
+// -----------------
+
+// Side identifie un camp dans le combat
+type Side int
+
+const (
+	SidePlayer Side = iota // Le joueur (à gauche)
+	SideEnemy              // L'adversaire (à droite)
+)
+
+// Other retourne le camp adverse
+func (s Side) Other() Side {
+	if s == SidePlayer {
+		return SideEnemy
+	}
+	return SidePlayer
+}
+
+// String retourne le nom utilisé dans Battle.Winner ("player" ou "enemy")
+func (s Side) String() string {
+	if s == SidePlayer {
+		return "player"
+	}
+	return "enemy"
+}
+
+// Index des attaques dans moveDefs
+const (
+	MovePunchline = iota
+	MoveFlow
+	MoveDissTrack
+	MoveMultiRhymes // Signature des Lyricistes
+	MoveStageShow   // Signature des Performeurs
+	MoveSummerHit   // Signature des Hitmakers
+	MoveBlock       // Défense : bloquer
+	MoveDodge       // Défense : esquiver
+	MoveCounter     // Défense : renvoyer la punchline
+	MoveBreathe     // Respirer : regagner du flow
+	MoveFreestyle   // Freestyle écrit au clavier : dégâts selon la note du texte
+)
+
+// MoveKind est la famille d'une attaque, utilisée par les défenses (pierre-feuille-ciseaux)
+type MoveKind int
+
+const (
+	KindPunchline MoveKind = iota // Attaques précises
+	KindFlow                      // Attaques rapides
+	KindHeavy                     // Attaques lourdes
+	KindDefense                   // Défenses (aucun dégât)
+	KindRest                      // Récupération (aucun dégât)
+)
+
+// MoveDef décrit une attaque
+type MoveDef struct {
+	Key      string   `json:"key"`                 // Identifiant stable (fichiers de données)
+	Name     string   `json:"name"`                // Nom affiché
+	Kind     MoveKind `json:"kind"`                // Famille de l'attaque
+	Damage   int      `json:"damage,omitempty"`    // Dégâts infligés
+	Heal     int      `json:"heal,omitempty"`      // Ego rendu au lanceur
+	Cooldown int      `json:"cooldown,omitempty"`  // Tours d'attente avant de pouvoir la relancer (0 = aucun)
+	Cost     int      `json:"cost,omitempty"`      // Flow dépensé pour la lancer
+	FlowGain int      `json:"flow_gain,omitempty"` // Flow regagné par le lanceur
+
+	Initiative       int `json:"initiative,omitempty"`        // Vitesse gagnée (ou perdue) par le lanceur au tour suivant
+	TargetInitiative int `json:"target_initiative,omitempty"` // Vitesse gagnée (ou perdue) par la cible au tour suivant
+}
+
+// Hits indique si l'attaque touche l'adversaire (les défenses et Respirer ne le visent pas)
+func (m MoveDef) Hits() bool {
+	return m.Kind != KindDefense && m.Kind != KindRest
+}
+
+// Identifiants des attaques, par index (leurs stats sont dans assets/classes.json, voir classes.go)
+var moveKeys = []string{
+	MovePunchline:   "punchline",
+	MoveFlow:        "flow",
+	MoveDissTrack:   "diss_track",
+	MoveMultiRhymes: "multi_rhymes",
+	MoveStageShow:   "stage_show",
+	MoveSummerHit:   "summer_hit",
+	MoveBlock:       "block",
+	MoveDodge:       "dodge",
+	MoveCounter:     "counter",
+	MoveBreathe:     "breathe",
+	MoveFreestyle:   "freestyle",
+}
+
+// moveIndex retourne l'index d'une attaque à partir de son identifiant
+func moveIndex(key string) (int, bool) {
+	for i, k := range moveKeys {
+		if k == key {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Réserve de flow des combattants sans stat de flow (anciens journaux de combat)
+const defaultMaxFlow = 10
+
+// baseMoves sont les attaques connues de tous les combattants
+var baseMoves = []int{MovePunchline, MoveFlow, MoveDissTrack}
+
+// defenseMoves sont les défenses connues de tous les combattants
+var defenseMoves = []int{MoveBlock, MoveDodge, MoveCounter}
+
+// guardRules donne, pour chaque défense, le % de dégâts encaissés selon la famille de l'attaque.
+// Chaque défense arrête une famille et en craint une autre :
+// bloquer arrête le lourd, esquiver le flow, renvoyer la punchline... les punchlines.
+var guardRules = map[int]map[MoveKind]int{
+	MoveBlock:   {KindHeavy: 0, KindPunchline: 100, KindFlow: 150},
+	MoveDodge:   {KindFlow: 0, KindHeavy: 100, KindPunchline: 150},
+	MoveCounter: {KindPunchline: 0, KindFlow: 100, KindHeavy: 150},
+}
+
+// La défense qui renvoie les dégâts qu'elle arrête
+const reflectGuard = MoveCounter
+
+// noGuard indique qu'un camp n'est pas en garde
+const noGuard = -1
+
+// Chance de coup critique (en %) et multiplicateur de dégâts associé (x1,5)
+const (
+	critChance    = 10
+	critNumerator = 3
+	critDivisor   = 2
+)
+
+// Points de charisme pour 1 % de chance de critique en plus
+const charismaPerCrit = 5
+
+// Dégâts d'un freestyle noté 100/100
+const freestyleMaxDamage = 40
+
+// -----------------
+// Événements de combat
+// -----------------
+
+// BattleEventType est le type d'un événement enregistré pendant un combat
+type BattleEventType int
+
+const (
+	EventMoveChosen BattleEventType = iota // Un camp a choisi son attaque
+	EventDamage                            // Des dégâts ont été infligés
+	EventStatus                            // Un statut a été appliqué (bonus, malus...)
+	EventDeath                             // Un camp n'a plus d'ego
+	EventHeal                              // Un camp a récupéré de l'ego
+	EventGuard                             // Une défense a joué (Status : "parade", "renvoi" ou "faille")
+	EventFlow                              // Un camp a regagné du flow
+	EventInitiative                        // La vitesse d'un camp change pour le tour suivant
+	EventOrder                             // Ordre du tour décidé (Actor : camp qui commence, Crit : égalité tirée au sort)
+	EventFreestyle                         // Un camp a rappé un freestyle (Amount : note sur 100, Status : le texte)
+)
+
+// BattleEvent décrit une étape du combat (sérialisée dans le journal des combats)
+type BattleEvent struct {
+	Turn   int             `json:"turn"`             // Numéro du tour
+	Type   BattleEventType `json:"type"`             // Type d'événement
+	Actor  Side            `json:"actor"`            // Camp à l'origine de l'événement
+	Target Side            `json:"target"`           // Camp qui subit l'événement
+	Move   int             `json:"move"`             // Attaque concernée
+	Amount int             `json:"amount,omitempty"` // Dégâts ou valeur du statut
+	Crit   bool            `json:"crit,omitempty"`   // Coup critique ?
+	Status string          `json:"status,omitempty"` // Nom du statut appliqué
+}
+
+// -----------------
+// Moteur de combat
+// -----------------
+
+// Fighter contient l'état d'un combattant, sans rien de graphique
+type Fighter struct {
+	Name  string `json:"name"`            // Nom affiché
+	Ego   int    `json:"ego"`             // Ego actuel (points de vie)
+	Class string `json:"class,omitempty"` // Classe (passif et attaque signature), vide pour un ennemi
+
+	Flow    int `json:"flow,omitempty"`     // Flow disponible pour lancer des attaques
+	MaxFlow int `json:"max_flow,omitempty"` // Réserve maximum de flow (stat Flow du perso)
+
+	Charisma  int              `json:"charisma,omitempty"`   // Charisme (équipement compris) : augmente la chance de critique
+	CritBonus map[MoveKind]int `json:"crit_bonus,omitempty"` // Chance de critique en plus par famille d'attaques (équipement)
+}
+
+// Combat est le moteur de règles : il applique les attaques et journalise les événements.
+// Tout le hasard passe par sa seed, donc un combat peut être rejoué à l'identique.
+type Combat struct {
+	Fighters [2]Fighter    // Combattants, indexés par Side
+	Turn     int           // Tour courant (commence à 1)
+	Seed     uint64        // Seed du générateur
+	Log      []BattleEvent // Tous les événements depuis le début du combat
+
+	src       *mrand.PCG // Générateur aléatoire du combat
+	cooldowns [2][]int   // Tours d'attente restants par camp et par attaque
+	guard     [2]int     // Défense active de chaque camp (noGuard sinon)
+	guardTurn [2]int     // Tour où la défense a été levée
+	initMod   [2]int     // Modificateurs de vitesse pour le prochain calcul de l'ordre
+	order     [2]Side    // Ordre d'action du tour courant
+	acted     int        // Nombre d'actions déjà choisies ce tour-ci
+	freestyle [2]bool    // Camps qui peuvent rapper un freestyle (joués au clavier)
+	barScore  [2]int     // Note du dernier freestyle de chaque camp (sur 100)
+}
+
+// NewCombat crée un moteur de combat à partir d'une seed et des deux combattants
+func NewCombat(seed uint64, player, enemy Fighter) *Combat {
+	for _, f := range []*Fighter{&player, &enemy} {
+		if f.MaxFlow <= 0 {
+			f.MaxFlow = defaultMaxFlow
+			f.Flow = f.MaxFlow
+		}
+	}
+	c := &Combat{
+		Fighters: [2]Fighter{player, enemy},
+		Turn:     1,
+		Seed:     seed,
+		src:      mrand.NewPCG(seed, seed),
+		cooldowns: [2][]int{
+			make([]int, len(moveDefs())),
+			make([]int, len(moveDefs())),
+		},
+		guard: [2]int{noGuard, noGuard},
+	}
+	c.decideOrder()
+	return c
+}
+
+// Rand retourne un générateur qui consomme l'état aléatoire du combat
+func (c *Combat) Rand() *mrand.Rand {
+	return mrand.New(c.src)
+}
+
+// Fighter retourne le combattant d'un camp
+func (c *Combat) Fighter(s Side) *Fighter {
+	return &c.Fighters[s]
+}
+
+// Moves retourne les actions d'un camp : les attaques de base, sa signature de classe,
+// le freestyle s'il est joué au clavier, les défenses puis Respirer
+func (c *Combat) Moves(s Side) []int {
+	moves := append([]int{}, baseMoves...)
+	if def, ok := ClassFor(c.Fighters[s].Class); ok {
+		moves = append(moves, def.Signature)
+	}
+	if c.freestyle[s] {
+		moves = append(moves, MoveFreestyle)
+	}
+	moves = append(moves, defenseMoves...)
+	return append(moves, MoveBreathe)
+}
+
+// EnableFreestyle donne le freestyle à un camp joué au clavier
+func (c *Combat) EnableFreestyle(s Side) {
+	c.freestyle[s] = true
+}
+
+// RecordBar enregistre le texte rappé par un camp et sa note (sur 100), utilisée par son freestyle
+func (c *Combat) RecordBar(actor Side, bar string, score int) BattleEvent {
+	score = min(max(score, 0), 100)
+	c.barScore[actor] = score
+	return c.emit(BattleEvent{Type: EventFreestyle, Actor: actor, Target: actor.Other(), Move: MoveFreestyle, Amount: score, Status: bar})
+}
+
+// CanAfford indique si un camp a assez de flow pour une attaque
+func (c *Combat) CanAfford(s Side, move int) bool {
+	return c.Fighters[s].Flow >= moveDefs()[move].Cost
+}
+
+// Speed retourne la vitesse d'un camp pour le prochain calcul de l'ordre (stat Flow + modificateurs)
+func (c *Combat) Speed(s Side) int {
+	return c.Fighters[s].MaxFlow + c.initMod[s]
+}
+
+// Order retourne l'ordre d'action du tour courant
+func (c *Combat) Order() [2]Side {
+	return c.order
+}
+
+// decideOrder calcule l'ordre du tour : le plus rapide commence, égalité tirée avec la seed
+func (c *Combat) decideOrder() {
+	speeds := [2]int{c.Speed(SidePlayer), c.Speed(SideEnemy)}
+	first := SidePlayer
+	tie := speeds[SidePlayer] == speeds[SideEnemy]
+	switch {
+	case tie:
+		first = Side(c.Rand().IntN(2))
+	case speeds[SideEnemy] > speeds[SidePlayer]:
+		first = SideEnemy
+	}
+	c.order = [2]Side{first, first.Other()}
+	c.initMod = [2]int{}
+	c.emit(BattleEvent{Type: EventOrder, Actor: first, Target: first.Other(), Amount: speeds[first], Crit: tie})
+}
+
+// Guard retourne la défense active d'un camp (noGuard s'il n'est pas en garde)
+func (c *Combat) Guard(s Side) int {
+	return c.guard[s]
+}
+
+// Cooldown retourne le nombre de tours avant qu'un camp puisse relancer une attaque
+func (c *Combat) Cooldown(s Side, move int) int {
+	return c.cooldowns[s][move]
+}
+
+// CanUse indique si un camp connaît une attaque et peut la lancer ce tour-ci
+func (c *Combat) CanUse(s Side, move int) bool {
+	if c.Cooldown(s, move) > 0 || !c.CanAfford(s, move) {
+		return false
+	}
+	for _, m := range c.Moves(s) {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
+
+// emit ajoute un événement au journal et le retourne
+func (c *Combat) emit(ev BattleEvent) BattleEvent {
+	ev.Turn = c.Turn
+	c.Log = append(c.Log, ev)
+	return ev
+}
+
+// ApplyStatus applique un statut à un camp (bonus d'ego positif, malus négatif)
+func (c *Combat) ApplyStatus(target Side, status string, amount int) BattleEvent {
+	f := c.Fighter(target)
+	f.Ego += amount
+	if f.Ego < 0 {
+		f.Ego = 0
+	}
+	return c.emit(BattleEvent{Type: EventStatus, Actor: target, Target: target, Status: status, Amount: amount})
+}
+
+// ChooseMove enregistre l'attaque choisie par un camp et lance son temps de recharge
+func (c *Combat) ChooseMove(actor Side, move int) BattleEvent {
+	c.cooldowns[actor][move] = moveDefs()[move].Cooldown
+	c.acted++
+	f := c.Fighter(actor)
+	f.Flow = max(0, f.Flow-moveDefs()[move].Cost)
+	return c.emit(BattleEvent{Type: EventMoveChosen, Actor: actor, Target: actor.Other(), Move: move})
+}
+
+// ResolveMove applique les dégâts d'une attaque et retourne les événements produits
+func (c *Combat) ResolveMove(actor Side, move int) []BattleEvent {
+	target := actor.Other()
+	def := moveDefs()[move]
+
+	var events []BattleEvent
+	// Flow regagné par le lanceur (plafonné à sa réserve)
+	if def.FlowGain > 0 {
+		f := c.Fighter(actor)
+		gain := min(def.FlowGain, f.MaxFlow-f.Flow)
+		if gain > 0 {
+			f.Flow += gain
+			events = append(events, c.emit(BattleEvent{Type: EventFlow, Actor: actor, Target: actor, Move: move, Amount: gain}))
+		}
+	}
+
+	// Changements d'initiative pour le tour suivant
+	if def.Initiative != 0 {
+		events = append(events, c.ApplyInitiative(actor, move, def.Initiative))
+	}
+	if def.TargetInitiative != 0 {
+		events = append(events, c.ApplyInitiative(target, move, def.TargetInitiative))
+	}
+
+	switch def.Kind {
+	case KindDefense:
+		// Défense : le camp se met en garde jusqu'à la fin du tour suivant
+		c.guard[actor] = move
+		c.guardTurn[actor] = c.Turn
+		return events
+	case KindRest:
+		return events
+	}
+
+	dmg := def.Damage
+	if move == MoveFreestyle {
+		dmg = c.barScore[actor] * freestyleMaxDamage / 100 // Dégâts selon la note du texte
+	}
+	chance := critChance + c.Fighters[actor].Charisma/charismaPerCrit + c.Fighters[actor].CritBonus[def.Kind]
+	// Passif de classe du lanceur
+	if class, ok := ClassFor(c.Fighters[actor].Class); ok {
+		dmg += class.Passive.DamageBonus[move]
+		chance += class.Passive.CritBonus[move]
+	}
+	crit := c.Rand().IntN(100) < chance
+	if crit {
+		dmg = dmg * critNumerator / critDivisor
+	}
+
+	// La garde de la cible modifie les dégâts, puis tombe
+	reflected := 0
+	if guard := c.guard[target]; guard != noGuard {
+		c.guard[target] = noGuard
+		pct := guardRules[guard][def.Kind]
+		outcome := "parade"
+		switch {
+		case pct == 0 && guard == reflectGuard:
+			outcome = "renvoi"
+			reflected = dmg
+		case pct > 100:
+			outcome = "faille"
+		}
+		dmg = dmg * pct / 100
+		events = append(events, c.emit(BattleEvent{Type: EventGuard, Actor: target, Target: actor, Move: guard, Status: outcome}))
+	}
+
+	f := c.Fighter(target)
+	f.Ego -= dmg
+	events = append(events, c.emit(BattleEvent{Type: EventDamage, Actor: actor, Target: target, Move: move, Amount: dmg, Crit: crit}))
+
+	// Punchline renvoyée : le lanceur prend ses propres dégâts
+	if reflected > 0 {
+		c.Fighter(actor).Ego -= reflected
+		events = append(events, c.emit(BattleEvent{Type: EventDamage, Actor: target, Target: actor, Move: reflectGuard, Amount: reflected}))
+	}
+	if def.Heal > 0 {
+		c.Fighter(actor).Ego += def.Heal
+		events = append(events, c.emit(BattleEvent{Type: EventHeal, Actor: actor, Target: actor, Move: move, Amount: def.Heal}))
+	}
+	if f.Ego <= 0 {
+		events = append(events, c.emit(BattleEvent{Type: EventDeath, Actor: actor, Target: target, Move: move}))
+	}
+	if c.Fighter(actor).Ego <= 0 {
+		events = append(events, c.emit(BattleEvent{Type: EventDeath, Actor: target, Target: actor, Move: move}))
+	}
+	return events
+}
+
+// ApplyInitiative modifie la vitesse d'un camp pour le calcul de l'ordre du tour suivant
+func (c *Combat) ApplyInitiative(target Side, move, amount int) BattleEvent {
+	c.initMod[target] += amount
+	return c.emit(BattleEvent{Type: EventInitiative, Actor: target, Target: target, Move: move, Amount: amount})
+}
+
+// EndTurn passe au tour suivant, fait avancer les temps de recharge et décide du nouvel ordre.
+// Une garde levée avant ce tour tombe : elle couvre au plus le reste de son tour et le suivant.
+func (c *Combat) EndTurn() {
+	for s := range c.guard {
+		if c.guard[s] != noGuard && c.guardTurn[s] < c.Turn {
+			c.guard[s] = noGuard
+		}
+	}
+	c.Turn++
+	c.acted = 0
+	for s := range c.cooldowns {
+		for m, cd := range c.cooldowns[s] {
+			if cd > 0 {
+				c.cooldowns[s][m] = cd - 1
+			}
+		}
+	}
+	c.decideOrder()
+}
+
+// Clone copie le combat pour une simulation, avec un nouveau générateur :
+// celui qui simule ne doit pas connaître les coups critiques à venir.
+// Le journal n'est pas copié.
+func (c *Combat) Clone(seed uint64) *Combat {
+	clone := *c
+	clone.Log = nil
+	clone.src = mrand.NewPCG(seed, ^seed)
+	for s := range c.cooldowns {
+		clone.cooldowns[s] = append([]int(nil), c.cooldowns[s]...)
+	}
+	return &clone
+}
+
+// Step joue une action complète (choix puis résolution), comme l'écran de combat,
+// et retourne le prochain camp à jouer, ou false si un camp est KO
+func (c *Combat) Step(actor Side, move int) (Side, bool) {
+	c.ChooseMove(actor, move)
+	c.ResolveMove(actor, move)
+	if c.Fighters[SidePlayer].Ego <= 0 || c.Fighters[SideEnemy].Ego <= 0 {
+		return actor, false
+	}
+	if c.acted < len(c.order) {
+		return actor.Other(), true
+	}
+	c.EndTurn()
+	return c.order[0], true
+}
+
+// Hash retourne une empreinte de l'état du combat (tour, ego, flow, gardes, initiative, freestyle, recharges et générateur).
+// En réseau, les deux clients comparent leurs empreintes pour détecter une désynchronisation.
+func (c *Combat) Hash() uint64 {
+	h := fnv.New64a()
+	buf := binary.LittleEndian.AppendUint64(nil, uint64(c.Turn))
+	for _, f := range c.Fighters {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(f.Ego))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(f.Flow))
+	}
+	for s := range c.guard {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(c.guard[s]))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(c.initMod[s]))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(c.barScore[s]))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(c.order[s]))
+	}
+	for _, cds := range c.cooldowns {
+		for _, cd := range cds {
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(cd))
+		}
+	}
+	state, _ := c.src.MarshalBinary() // Ne peut pas échouer pour un PCG
+	h.Write(buf)
+	h.Write(state)
+	return h.Sum64()
+}
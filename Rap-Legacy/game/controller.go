@@ -0,0 +1,268 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	mrand "math/rand/v2" // Générateur aléatoire propre à l'IA
+
+	"github.com/hajimehoshi/ebiten/v2"           // Ebiten, moteur 2D
+	"github.com/hajimehoshi/ebiten/v2/inpututil" // Pour détecter les appuis uniques
+)
+
+// BattleController choisit les attaques d'un camp (clavier, IA, replay...)
+type BattleController interface {
+	// ChooseMove est appelé à chaque tick tant que le camp doit choisir.
+	// Retourne l'attaque et true quand le choix est fait, false pour attendre le tick suivant.
+	ChooseMove(b *Battle, side Side) (int, bool)
+}
+
+// battleObserver est implémenté par les contrôleurs qui suivent tout le combat (réseau)
+type battleObserver interface {
+	Tick(b *Battle)      // Appelé à chaque tick, quel que soit l'état du combat
+	TurnEnded(b *Battle) // Appelé quand un tour est entièrement résolu
+}
+
+// menuController est implémenté par les contrôleurs qui affichent le menu des attaques
+type menuController interface {
+	Menu() *HumanController
+}
+
+// -----------------
+// Joueur humain
+// -----------------
+
+// KeySet regroupe les touches d'un joueur humain
+type KeySet struct {
+	Up, Down, Confirm ebiten.Key
+	Hint              string // Rappel des touches affiché sous le menu
+}
+
+// Touches du joueur de gauche en versus (ZQSD physique) et du joueur solo / de droite
+var (
+	KeysLeft  = KeySet{Up: ebiten.KeyW, Down: ebiten.KeyS, Confirm: ebiten.KeySpace, Hint: "Z/S + Espace"}
+	KeysArrow = KeySet{Up: ebiten.KeyArrowUp, Down: ebiten.KeyArrowDown, Confirm: ebiten.KeyEnter, Hint: "Flèches + Entrée"}
+)
+
+// HumanController lit le clavier pour choisir une attaque dans le menu
+type HumanController struct {
+	Keys     KeySet
+	selected int // Option surlignée dans le menu
+}
+
+// NewHumanController crée un contrôleur clavier avec le jeu de touches donné
+func NewHumanController(keys KeySet) *HumanController {
+	return &HumanController{Keys: keys}
+}
+
+// Menu retourne le contrôleur clavier dont le menu est affiché
+func (h *HumanController) Menu() *HumanController {
+	return h
+}
+
+func (h *HumanController) ChooseMove(b *Battle, side Side) (int, bool) {
+	moves := b.combat.Moves(side)
+	n := len(moves)
+	// Navigation dans le menu avec bouclage
+	if inpututil.IsKeyJustPressed(h.Keys.Down) {
+		h.selected = (h.selected + 1) % n
+	}
+	if inpututil.IsKeyJustPressed(h.Keys.Up) {
+		h.selected = (h.selected + n - 1) % n
+	}
+	// Validation de l'attaque (ignorée tant qu'elle recharge)
+	if inpututil.IsKeyJustPressed(h.Keys.Confirm) && b.combat.CanUse(side, moves[h.selected]) {
+		return moves[h.selected], true
+	}
+	return 0, false
+}
+
+// -----------------
+// IA
+// -----------------
+
+// AIController choisit ses attaques avec son propre générateur
+// pour ne pas décaler le hasard du moteur (coups critiques, butin).
+// Elle lit les habitudes de l'adversaire dans le journal (jamais sa garde en cours) :
+// elle contre l'attaque qu'il enchaîne le plus souvent et frappe la faille de sa défense favorite.
+type AIController struct {
+	rng *mrand.Rand
+}
+
+// NewAIController crée une IA dont les choix dépendent de la seed du combat
+func NewAIController(seed uint64) *AIController {
+	return &AIController{rng: mrand.New(mrand.NewPCG(seed, ^seed))}
+}
+
+// Nombre minimum d'observations avant que l'IA se fie à une lecture
+const aiMinSamples = 3
+
+func (a *AIController) ChooseMove(b *Battle, side Side) (int, bool) {
+	return a.Pick(b.combat, side), true
+}
+
+// Pick choisit une action à partir du seul moteur de combat
+func (a *AIController) Pick(c *Combat, side Side) int {
+	// Lecture des habitudes : on exploite l'action que l'adversaire enchaîne le plus souvent
+	if action, confidence := predictAction(c.Log, side.Other(), c.Turn); confidence >= 50 && a.rng.IntN(100) < confidence {
+		if action >= len(moveDefs()) {
+			// Il attaque : on se met dans la défense qui arrête cette famille
+			if def := counterFor(MoveKind(action - len(moveDefs()))); c.CanUse(side, def) {
+				return def
+			}
+		} else if move, ok := bestAttackVs(c, side, action); ok {
+			// Il se défend : on frappe là où sa défense est faible
+			return move
+		}
+	}
+
+	// Attaque signature dès qu'elle est prête, une fois sur quatre
+	if class, ok := ClassFor(c.Fighter(side).Class); ok && c.CanUse(side, class.Signature) && a.rng.IntN(4) == 0 {
+		return class.Signature
+	}
+
+	// Budget : à court de flow, l'IA souffle plutôt que d'enchaîner des petites attaques
+	f := c.Fighter(side)
+	if f.Flow < moveDefs()[MoveDissTrack].Cost && f.Flow < f.MaxFlow && a.rng.IntN(100) < 40 {
+		return MoveBreathe
+	}
+
+	move := MoveDissTrack // 20%
+	r := a.rng.IntN(100)  // 0-99
+	if r < 50 {           // 50%
+		move = MovePunchline
+	} else if r < 80 { // 30%
+		move = MoveFlow
+	}
+	if !c.CanUse(side, move) {
+		// Pas assez de flow : on se rabat sur le Flow (gratuit) ou on respire
+		move = MoveFlow
+		if f.Flow == 0 {
+			move = MoveBreathe
+		}
+	}
+	return move
+}
+
+// aiAction résume une action pour la lecture des habitudes :
+// une défense (ou Respirer) garde son index, une attaque devient len(moveDefs()) + sa famille
+func aiAction(move int) int {
+	if kind := moveDefs()[move].Kind; kind == KindDefense || kind == KindRest {
+		return move
+	}
+	return len(moveDefs()) + int(moveDefs()[move].Kind)
+}
+
+// predictAction devine la prochaine action d'un camp d'après ses tours précédents :
+// d'abord ce qu'il a joué après sa dernière action, sinon ses actions récentes.
+// Les actions du tour en cours sont ignorées (la garde choisie ce tour-ci reste secrète).
+// Retourne l'action la plus probable (voir aiAction) et la confiance (en %).
+func predictAction(log []BattleEvent, s Side, turn int) (int, int) {
+	var history []int
+	for _, ev := range log {
+		if ev.Type == EventMoveChosen && ev.Actor == s && ev.Turn < turn {
+			history = append(history, aiAction(ev.Move))
+		}
+	}
+	if len(history) == 0 {
+		return 0, 0
+	}
+
+	// Ce qui a suivi la dernière action les fois précédentes
+	last := history[len(history)-1]
+	counts := map[int]int{}
+	total := 0
+	for i := 0; i+1 < len(history); i++ {
+		if history[i] == last {
+			counts[history[i+1]]++
+			total++
+		}
+	}
+	// Pas assez de recul : les quatre dernières actions
+	if total < aiMinSamples {
+		counts = map[int]int{}
+		total = 0
+		for i := len(history) - 1; i >= 0 && total < 4; i-- {
+			counts[history[i]]++
+			total++
+		}
+	}
+	if total < aiMinSamples {
+		return 0, 0
+	}
+
+	best, bestCount := 0, -1
+	for action, n := range counts {
+		if n > bestCount || (n == bestCount && action < best) { // Départage stable (ordre des maps aléatoire)
+			best, bestCount = action, n
+		}
+	}
+	return best, bestCount * 100 / total
+}
+
+// counterFor retourne la défense qui arrête une famille d'attaques
+func counterFor(kind MoveKind) int {
+	for _, def := range defenseMoves {
+		if guardRules[def][kind] == 0 {
+			return def
+		}
+	}
+	return MoveBlock
+}
+
+// bestAttackVs retourne l'attaque disponible qui fait le plus de dégâts à travers une garde
+func bestAttackVs(c *Combat, s Side, guard int) (int, bool) {
+	best, bestDmg := 0, 0
+	for _, m := range c.Moves(s) {
+		def := moveDefs()[m]
+		if def.Kind == KindDefense || !c.CanUse(s, m) {
+			continue
+		}
+		if dmg := def.Damage * guardRules[guard][def.Kind]; dmg > bestDmg {
+			best, bestDmg = m, dmg
+		}
+	}
+	return best, bestDmg > 0
+}
+
+// -----------------
+// Replay
+// -----------------
+
+// ReplayController rejoue les attaques enregistrées d'un camp
+type ReplayController struct {
+	moves []int
+	next  int
+	bars  []BattleEvent // Freestyles enregistrés (texte et note)
+}
+
+// NewReplayController extrait les attaques d'un camp depuis un journal
+func NewReplayController(events []BattleEvent, side Side) *ReplayController {
+	rc := &ReplayController{}
+	for _, ev := range events {
+		if ev.Type == EventMoveChosen && ev.Actor == side {
+			rc.moves = append(rc.moves, ev.Move)
+		}
+		if ev.Type == EventFreestyle && ev.Actor == side {
+			rc.bars = append(rc.bars, ev)
+		}
+	}
+	return rc
+}
+
+func (r *ReplayController) ChooseMove(b *Battle, side Side) (int, bool) {
+	if r.next >= len(r.moves) {
+		b.exitRequested = true // Journal incomplet : fin du replay
+		return 0, false
+	}
+	move := r.moves[r.next]
+	r.next++
+	return move, true
+}
+
+// NextBar retourne le prochain freestyle enregistré (texte et note)
+func (r *ReplayController) NextBar() (string, int) {
+	if len(r.bars) == 0 {
+		return "", 0
+	}
+	ev := r.bars[0]
+	r.bars = r.bars[1:]
+	return ev.Status, ev.Amount
+}
@@ -0,0 +1,279 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"encoding/json" // Les répliques sont décrites en JSON
+	"image/color"   // Couleurs de la bulle
+	"log"           // Erreurs de chargement
+	"math/rand"     // Tirage des répliques (cosmétique : hors du hasard du moteur)
+	"os"            // Lecture du fichier
+	"strings"       // Découpage des répliques en lignes
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// -----------------
+// Dialogues de combat
+// -----------------
+
+// Fichier des répliques
+const dialoguePath = "assets/dialogue.json"
+
+// Situations d'une réplique
+const (
+	CtxFirstTurn = "first_turn" // Premier tour du combat (facultatif : les répliques ordinaires restent possibles)
+	CtxLowEgo    = "low_ego"    // Ego du locuteur bas (facultatif)
+	CtxCrit      = "crit"       // Le locuteur vient de placer un coup critique
+	CtxVictory   = "victory"    // Le locuteur a gagné
+	CtxDefeat    = "defeat"     // Le locuteur a perdu
+)
+
+// Rôles des locuteurs
+const (
+	SpeakerPlayer = "player" // Le joueur (les deux camps en versus)
+	SpeakerEnemy  = "enemy"  // L'ennemi joué par l'IA
+)
+
+// Seuil d'ego bas, en % de l'ego de départ
+const lowEgoPercent = 30
+
+// Nombre de répliques récentes d'un camp qu'il ne répète pas
+const dialogueMemory = 3
+
+// Noms des familles d'attaques dans le fichier
+var kindKeys = map[MoveKind]string{
+	KindPunchline: "punchline",
+	KindFlow:      "flow",
+	KindHeavy:     "heavy",
+	KindDefense:   "defense",
+	KindRest:      "rest",
+}
+
+// DialogueLine est une réplique et les conditions pour la dire ; un champ vide accepte tout
+type DialogueLine struct {
+	Speaker string `json:"speaker,omitempty"` // "player" ou "enemy"
+	Move    string `json:"move,omitempty"`    // Clé de l'attaque (MoveDef.Key)
+	Kind    string `json:"kind,omitempty"`    // Famille de l'attaque (voir kindKeys)
+	Context string `json:"context,omitempty"` // Situation (Ctx...) ; vide = attaque ordinaire
+	Enemy   string `json:"enemy,omitempty"`   // Nom de l'ennemi du combat
+	Weight  int    `json:"weight,omitempty"`  // Poids du tirage (1 par défaut)
+	Text    string `json:"text"`
+}
+
+// DialogueQuery décrit le moment où un camp prend la parole
+type DialogueQuery struct {
+	Side       Side     // Camp qui parle (pour ne pas se répéter)
+	Speaker    string   // Rôle du locuteur
+	Move       int      // Attaque concernée (-1 : aucune)
+	Context    string   // Situation obligatoire (crit, victoire, défaite) ; vide pour une attaque
+	Situations []string // Situations facultatives (premier tour, ego bas)
+	Enemy      string   // Nom de l'ennemi du combat
+
+	// Pour le générateur de punchlines (quand aucune réplique écrite ne convient)
+	Opponent string   // Nom de l'adversaire à interpeller ("" : sans nom)
+	Class    string   // Classe du locuteur
+	Items    []string // Objets du locuteur
+}
+
+// Dialogue choisit les répliques d'un combat
+type Dialogue struct {
+	lines  []DialogueLine
+	recent [2][]string   // Dernières répliques de chaque camp
+	gen    *PunchlineGen // Répliques fabriquées quand aucune réplique écrite ne convient (peut être nil)
+}
+
+// LoadDialogue charge les répliques (aucune réplique si le fichier est absent ou invalide).
+// gen fabrique une réplique pour les situations qu'aucune réplique écrite ne couvre.
+func LoadDialogue(path string, gen *PunchlineGen) *Dialogue {
+	d := &Dialogue{gen: gen}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Println("Impossible de charger les dialogues :", err)
+		return d
+	}
+	if err := json.Unmarshal(data, &d.lines); err != nil {
+		log.Println("Dialogues invalides :", err)
+	}
+	return d
+}
+
+// score retourne la précision d'une réplique pour la situation, ou -1 si elle ne convient pas.
+// Les répliques les plus précises passent en premier.
+func (l DialogueLine) score(q DialogueQuery) int {
+	if l.Text == "" || (l.Speaker != "" && l.Speaker != q.Speaker) || (l.Enemy != "" && l.Enemy != q.Enemy) {
+		return -1
+	}
+	score := 0
+	if l.Move != "" || l.Kind != "" {
+		if q.Move < 0 {
+			return -1
+		}
+		def := moveDefs()[q.Move]
+		if (l.Move != "" && l.Move != def.Key) || (l.Kind != "" && l.Kind != kindKeys[def.Kind]) {
+			return -1
+		}
+		if l.Move != "" {
+			score += 2
+		} else {
+			score++
+		}
+	}
+	switch {
+	case l.Context == q.Context:
+		if l.Context != "" {
+			score += 8
+		}
+	case q.Context == "" && contains(q.Situations, l.Context):
+		score += 8
+	default:
+		return -1
+	}
+	if l.Enemy != "" {
+		score += 4
+	}
+	return score
+}
+
+// contains indique si list contient s
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Pick tire une réplique pour la situation : parmi les plus précises, au poids,
+// sans reprendre une réplique récente du même camp tant qu'il en reste d'autres.
+func (d *Dialogue) Pick(q DialogueQuery) (string, bool) {
+	// Regroupe les répliques possibles par précision
+	tiers := map[int][]DialogueLine{}
+	best := -1
+	for _, l := range d.lines {
+		if s := l.score(q); s >= 0 {
+			tiers[s] = append(tiers[s], l)
+			best = max(best, s)
+		}
+	}
+	if best < 0 {
+		return d.generate(q)
+	}
+
+	// Du plus précis au moins précis : premier groupe avec une réplique pas encore dite récemment
+	for s := best; s >= 0; s-- {
+		var fresh []DialogueLine
+		for _, l := range tiers[s] {
+			if !contains(d.recent[q.Side], l.Text) {
+				fresh = append(fresh, l)
+			}
+		}
+		if len(fresh) > 0 {
+			return d.remember(q.Side, weightedLine(fresh)), true
+		}
+	}
+	// Tout a été dit récemment : on se répète plutôt que de se taire
+	return d.remember(q.Side, weightedLine(tiers[best])), true
+}
+
+// generate fabrique une réplique quand aucune réplique écrite ne convient
+func (d *Dialogue) generate(q DialogueQuery) (string, bool) {
+	if d.gen == nil {
+		return "", false
+	}
+	line := d.gen.Line(PunchlineInput{Opponent: q.Opponent, Class: q.Class, Items: q.Items, Move: q.Move, Context: q.Context})
+	if line == "" {
+		return "", false
+	}
+	return d.remember(q.Side, line), true
+}
+
+// remember ajoute une réplique aux dernières dites par un camp
+func (d *Dialogue) remember(side Side, line string) string {
+	d.recent[side] = append(d.recent[side], line)
+	if len(d.recent[side]) > dialogueMemory {
+		d.recent[side] = d.recent[side][1:]
+	}
+	return line
+}
+
+// weightedLine tire une réplique selon les poids
+func weightedLine(lines []DialogueLine) string {
+	total := 0
+	for _, l := range lines {
+		total += max(l.Weight, 1)
+	}
+	n := rand.Intn(total)
+	for _, l := range lines {
+		n -= max(l.Weight, 1)
+		if n < 0 {
+			return l.Text
+		}
+	}
+	return lines[len(lines)-1].Text
+}
+
+// -----------------
+// Bulle de dialogue
+// -----------------
+
+// Largeur maximale d'une ligne de bulle, en caractères
+const bubbleLineChars = 30
+
+// wrapText coupe un texte en lignes d'au plus width caractères (sans couper les mots)
+func wrapText(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// drawSpeechBubble dessine une bulle dont la pointe touche (cx, tipY)
+func drawSpeechBubble(screen *ebiten.Image, s string, face font.Face, cx, tipY float64) {
+	if face == nil {
+		face = basicfont.Face7x13
+	}
+	lines := wrapText(s, bubbleLineChars)
+	lineH := face.Metrics().Height.Ceil() + 4
+	textW := 0
+	for _, l := range lines {
+		textW = max(textW, text.BoundString(face, l).Dx())
+	}
+
+	const pad, tail = 12, 14
+	w := float64(textW + 2*pad)
+	h := float64(len(lines)*lineH + 2*pad)
+	x := cx - w/2
+	y := tipY - tail - h
+	// Garde la bulle dans l'écran
+	screenW, _ := screen.Size()
+	x = min(max(x, 10), float64(screenW)-w-10)
+
+	bg := color.RGBA{250, 250, 240, 235}
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h), bg, false)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 2, color.RGBA{30, 30, 30, 255}, false)
+	// Pointe vers le locuteur, ligne par ligne
+	for i := 0; i < tail; i++ {
+		half := float32(tail-i) / 2
+		vector.DrawFilledRect(screen, float32(cx)-half, float32(y+h)+float32(i), 2*half, 1, bg, false)
+	}
+
+	for i, l := range lines {
+		text.Draw(screen, l, face, int(x)+pad, int(y)+pad+(i+1)*lineH-4, color.RGBA{20, 20, 20, 255})
+	}
+}
@@ -7,6 +7,8 @@ type Enemy struct {
 	X, Y   float64       // Position de l'ennemi sur l'écran (coordonnées X et Y)
 	Name   string        // Nom de l'ennemi
 	Ego    int           // Niveau d'égo (points de vie) de l'ennemi
+	Flow   int           // Réserve de flow en combat
+	Boss   bool          // Boss : joue avec l'IA qui anticipe
 	sprite *ebiten.Image // Image représentant l'ennemi
 }
 
@@ -17,6 +19,7 @@ func NewEnemy(x, y float64, name string) *Enemy {
 		Y:      y,                                  // Initialise la position Y
 		Name:   name,                               // Initialise le nom
 		Ego:    100,                                // Initialise l'égo par défaut à 100
+		Flow:   10,                                 // Initialise le flow par défaut à 10
 		sprite: LoadImage("assets/enemy_idle.png"), // Charge l'image de l'ennemi
 	}
 }
@@ -0,0 +1,168 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"encoding/json" // Lecture de l'équipement des saves
+	"fmt"           // Description des bonus
+	"strings"       // Assemblage des bonus
+)
+
+// -----------------
+// Équipement
+// -----------------
+
+// Emplacements d'équipement (ItemDef.Slot)
+const (
+	SlotMic      = "mic"      // Micro
+	SlotChain    = "chain"    // Chaîne
+	SlotSneakers = "sneakers" // Baskets
+	SlotOutfit   = "outfit"   // Tenue
+)
+
+// Ordre d'affichage des emplacements
+var equipSlots = []string{SlotMic, SlotChain, SlotSneakers, SlotOutfit}
+
+// Noms affichés des emplacements
+var slotNames = map[string]string{
+	SlotMic:      "Micro",
+	SlotChain:    "Chaîne",
+	SlotSneakers: "Baskets",
+	SlotOutfit:   "Tenue",
+}
+
+// Stats regroupe les stats du joueur (ou les bonus d'un objet)
+type Stats struct {
+	Ego      int `json:"ego,omitempty"`      // Ego maximum
+	Flow     int `json:"flow,omitempty"`     // Flow
+	Charisma int `json:"charisma,omitempty"` // Charisme
+
+	// Chance de critique en plus (en %) par famille d'attaques (propriétés des équipements)
+	CritPunchline int `json:"crit_punchline,omitempty"`
+	CritFlow      int `json:"crit_flow,omitempty"`
+	CritHeavy     int `json:"crit_heavy,omitempty"`
+}
+
+// Add additionne deux jeux de stats
+func (s Stats) Add(o Stats) Stats {
+	return Stats{
+		Ego: s.Ego + o.Ego, Flow: s.Flow + o.Flow, Charisma: s.Charisma + o.Charisma,
+		CritPunchline: s.CritPunchline + o.CritPunchline, CritFlow: s.CritFlow + o.CritFlow, CritHeavy: s.CritHeavy + o.CritHeavy,
+	}
+}
+
+// String décrit des bonus ("+10 Ego, +2 Flow, +5% crit punchlines")
+func (s Stats) String() string {
+	var parts []string
+	for _, p := range []struct {
+		name  string
+		value int
+	}{{"Ego", s.Ego}, {"Flow", s.Flow}, {"Charisme", s.Charisma}} {
+		if p.value != 0 {
+			parts = append(parts, fmt.Sprintf("%+d %s", p.value, p.name))
+		}
+	}
+	for _, p := range []struct {
+		name  string
+		value int
+	}{{"punchlines", s.CritPunchline}, {"attaques rapides", s.CritFlow}, {"attaques lourdes", s.CritHeavy}} {
+		if p.value != 0 {
+			parts = append(parts, fmt.Sprintf("%+d%% crit %s", p.value, p.name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CritBonus retourne la chance de critique en plus par famille d'attaques (nil s'il n'y en a pas)
+func (s Stats) CritBonus() map[MoveKind]int {
+	if s.CritPunchline == 0 && s.CritFlow == 0 && s.CritHeavy == 0 {
+		return nil
+	}
+	return map[MoveKind]int{KindPunchline: s.CritPunchline, KindFlow: s.CritFlow, KindHeavy: s.CritHeavy}
+}
+
+// Equipment associe chaque emplacement à l'exemplaire porté (enregistré dans les saves)
+type Equipment map[string]Item
+
+// UnmarshalJSON lit l'équipement. Les premières saves n'enregistrent que l'identifiant de chaque objet.
+func (e *Equipment) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = Equipment{}
+	for slot, r := range raw {
+		var it Item
+		if err := json.Unmarshal(r, &it.ID); err != nil {
+			if err := json.Unmarshal(r, &it); err != nil {
+				return err
+			}
+		}
+		it.ID = ItemID(it.ID)
+		(*e)[slot] = it
+	}
+	return nil
+}
+
+// Bonus retourne le total des bonus des objets portés
+func (e Equipment) Bonus() Stats {
+	var total Stats
+	for _, it := range e {
+		total = total.Add(it.Stats())
+	}
+	return total
+}
+
+// Stats retourne les stats du joueur, équipement compris (Ego : ego maximum)
+func (p *Player) Stats() Stats {
+	return Stats{Ego: p.MaxEgo, Flow: p.Flow, Charisma: p.Charisma}.Add(p.Equipment.Bonus())
+}
+
+// canShiftEgo indique si l'ego max peut varier de delta : l'ego suit l'ego max, et un changement d'équipement ne
+// doit pas mettre le joueur KO. Un joueur déjà KO peut toujours changer d'équipement.
+func (p *Player) canShiftEgo(delta int) bool {
+	return p.Ego <= 0 || p.Ego+delta > 0
+}
+
+// shiftEgo fait suivre à l'ego un changement d'ego max (les dégâts encaissés restent les mêmes) ; un joueur KO reste
+// KO. Équiper puis retirer un objet rend donc exactement l'ego de départ.
+func (p *Player) shiftEgo(delta int) {
+	if p.Ego > 0 {
+		p.Ego = min(p.Ego+delta, p.Stats().Ego)
+	}
+}
+
+// Equip porte un exemplaire dans son emplacement. Retourne l'exemplaire qu'il remplace (ID vide si l'emplacement
+// était libre) et false si l'objet ne s'équipe pas (pas d'emplacement, ou l'échange mettrait le joueur KO).
+func (p *Player) Equip(it Item) (Item, bool) {
+	d, ok := LookupItem(it.ID)
+	if !ok || d.Slot == "" {
+		return Item{}, false
+	}
+	it.ID = d.ID
+	previous := p.Equipment[d.Slot]
+	delta := it.Stats().Ego - previous.Stats().Ego
+	if !p.canShiftEgo(delta) {
+		return Item{}, false
+	}
+	if p.Equipment == nil {
+		p.Equipment = Equipment{}
+	}
+	p.Equipment[d.Slot] = it
+	p.shiftEgo(delta) // Le bonus d'ego compte tout de suite, comme l'ego max
+	return previous, true
+}
+
+// Unequip libère un emplacement et retourne l'exemplaire retiré (ID vide si l'emplacement était libre), et false si
+// le retirer mettrait le joueur KO
+func (p *Player) Unequip(slot string) (Item, bool) {
+	it, ok := p.Equipment[slot]
+	if !ok {
+		return Item{}, true
+	}
+	delta := -it.Stats().Ego // L'ego apporté par l'objet repart avec lui
+	if !p.canShiftEgo(delta) {
+		return Item{}, false
+	}
+	delete(p.Equipment, slot)
+	p.shiftEgo(delta)
+	return it, true
+}
@@ -0,0 +1,82 @@
+package game
+
+import "testing"
+
+// equipOp est une action sur l'équipement : porter un objet (id) ou libérer un emplacement (slot)
+type equipOp struct {
+	id, slot string
+	ok       bool // Action acceptée ?
+	ego      int  // Ego attendu après l'action
+}
+
+func TestEquipRoundTrip(t *testing.T) {
+	t.Chdir("..")
+	for _, tc := range []struct {
+		name string
+		ego  int
+		worn []string
+		ops  []equipOp
+	}{
+		{"retirer à ego bas est refusé", 5, []string{"survetement"}, []equipOp{
+			{slot: SlotOutfit, ok: false, ego: 5},
+		}},
+		{"retirer puis remettre", 25, []string{"survetement"}, []equipOp{
+			{slot: SlotOutfit, ok: true, ego: 5},
+			{id: "survetement", ok: true, ego: 25},
+		}},
+		{"porter puis retirer", 5, nil, []equipOp{
+			{id: "survetement", ok: true, ego: 25},
+			{slot: SlotOutfit, ok: true, ego: 5},
+		}},
+		{"ego plein", 120, []string{"survetement"}, []equipOp{
+			{slot: SlotOutfit, ok: true, ego: 100},
+			{id: "survetement", ok: true, ego: 120},
+		}},
+		{"KO", 0, []string{"survetement"}, []equipOp{
+			{slot: SlotOutfit, ok: true, ego: 0},
+			{id: "survetement", ok: true, ego: 0},
+		}},
+		{"échange vers mieux puis retour", 3, []string{"micro"}, []equipOp{
+			{id: "micro_or", ok: true, ego: 8},
+			{id: "micro", ok: true, ego: 3},
+		}},
+		{"échange vers moins bien à ego bas", 4, []string{"micro_or"}, []equipOp{
+			{id: "micro", ok: false, ego: 4},
+		}},
+	} {
+		p := &Player{Ego: tc.ego, MaxEgo: 100}
+		for _, id := range tc.worn {
+			d, _ := LookupItem(id)
+			if p.Equipment == nil {
+				p.Equipment = Equipment{}
+			}
+			p.Equipment[d.Slot] = Item{ID: id}
+		}
+		for i, op := range tc.ops {
+			var ok bool
+			if op.id != "" {
+				_, ok = p.Equip(Item{ID: op.id})
+			} else {
+				_, ok = p.Unequip(op.slot)
+			}
+			if ok != op.ok || p.Ego != op.ego {
+				t.Errorf("%s, action %d : accepté %v, ego %d ; attendu %v, ego %d", tc.name, i, ok, p.Ego, op.ok, op.ego)
+			}
+		}
+	}
+}
+
+func TestEquipSwapNeverHeals(t *testing.T) {
+	t.Chdir("..")
+	for _, ego := range []int{1, 5, 21, 60} {
+		p := &Player{Ego: ego, MaxEgo: 100, Equipment: Equipment{SlotOutfit: {ID: "survetement"}}}
+		for range 10 {
+			if it, ok := p.Unequip(SlotOutfit); ok {
+				p.Equip(it)
+			}
+		}
+		if p.Ego != ego {
+			t.Errorf("ego %d après 10 échanges, %d au départ", p.Ego, ego)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"strings" // Découpage du texte en mots
+	"unicode" // Lettres et voyelles
+)
+
+// -----------------
+// Notation des freestyles
+// -----------------
+
+// Règles du freestyle
+const (
+	freestyleSeconds   = 15 // Temps pour écrire sa phrase
+	freestyleMaxChars  = 80 // Longueur maximale de la phrase
+	freestyleSyllables = 12 // Syllabes attendues sur la mesure (quatre temps de trois syllabes)
+)
+
+// Points maximum de chaque critère (total sur 100)
+const (
+	barRhymePoints   = 40 // Rime finale avec la dernière phrase de l'adversaire
+	barRhythmPoints  = 35 // Nombre de syllabes proche de la mesure
+	barVarietyPoints = 25 // Vocabulaire varié
+)
+
+// BarScore détaille la note d'une phrase rappée
+type BarScore struct {
+	Rhyme, Rhythm, Variety int // Points par critère
+	Syllables              int // Syllabes comptées
+	Total                  int // Note sur 100
+}
+
+// ScoreBar note une phrase : rime avec previous, syllabes par rapport à la mesure et variété des mots
+func ScoreBar(bar, previous string) BarScore {
+	words := barWords(bar)
+	if len(words) == 0 {
+		return BarScore{}
+	}
+	var s BarScore
+
+	// Rime : même son final, ou au moins les deux dernières lettres
+	if last := barWords(previous); len(last) > 0 {
+		mine, theirs := words[len(words)-1], last[len(last)-1]
+		switch {
+		case rhymeKey(mine) == rhymeKey(theirs):
+			s.Rhyme = barRhymePoints
+		case lastRunes(mine, 2) == lastRunes(theirs, 2):
+			s.Rhyme = barRhymePoints / 2
+		}
+	}
+
+	// Rythme : chaque syllabe d'écart à la mesure coûte des points
+	for _, w := range words {
+		s.Syllables += countSyllables(w)
+	}
+	gap := s.Syllables - freestyleSyllables
+	if gap < 0 {
+		gap = -gap
+	}
+	s.Rhythm = max(barRhythmPoints-5*gap, 0)
+
+	// Variété : part de mots différents, réduite pour une phrase trop courte
+	unique := map[string]bool{}
+	for _, w := range words {
+		unique[w] = true
+	}
+	s.Variety = barVarietyPoints * len(unique) / len(words) * min(len(words), 6) / 6
+
+	s.Total = s.Rhyme + s.Rhythm + s.Variety
+	return s
+}
+
+// barWords retourne les mots d'une phrase, en minuscules et sans ponctuation
+func barWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+}
+
+// lastRunes retourne les n derniers caractères d'un mot
+func lastRunes(word string, n int) string {
+	r := []rune(word)
+	return string(r[max(len(r)-n, 0):])
+}
+
+// isVowel indique si une lettre est une voyelle (accents compris)
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiouyàâäéèêëîïôöùûüœ", r)
+}
+
+// countSyllables compte les syllabes d'un mot : les groupes de voyelles, sans le e muet final
+func countSyllables(word string) int {
+	count, inVowel := 0, false
+	for _, r := range word {
+		v := isVowel(r)
+		if v && !inVowel {
+			count++
+		}
+		inVowel = v
+	}
+	// "rime", "rimes" : le e final après une consonne ne se prononce pas ("partie", "année" gardent le leur)
+	r := []rune(strings.TrimSuffix(word, "s"))
+	if count > 1 && len(r) > 1 && r[len(r)-1] == 'e' && !isVowel(r[len(r)-2]) {
+		count--
+	}
+	return max(count, 1)
+}
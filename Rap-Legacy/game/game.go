@@ -2,10 +2,13 @@ package game
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"image"
 	"image/color"
 	"log"
+	mrand "math/rand/v2"
+	"net"
 	"os"
 	"time"
 
@@ -24,9 +27,10 @@ import (
 // Button structure
 // -----------------
 type Button struct {
-	Rect   image.Rectangle
-	Label  string
-	Action func()
+	Rect      image.Rectangle
+	Label     string
+	Action    func()
+	ShowLabel bool // Le fond du menu ne contient pas ce bouton : on dessine son texte
 }
 
 // -----------------
@@ -43,47 +47,12 @@ const (
 	StatePlaying
 	StateMerchantMenu
 	StateBlacksmithMenu
+	StateReplay
+	StateBattleResults
+	StateVersus
+	StateNetwork
 )
 
-// -----------------
-// Merchant Items
-// -----------------
-type MerchantItem struct {
-	Name   string
-	Price  int
-	Action func(g *Game)
-}
-
-// Liste des articles vendus par le marchand
-var MerchantItems = []MerchantItem{
-	{
-		Name:  "Cristalline",
-		Price: 50,
-		Action: func(g *Game) {
-			if g.player != nil {
-				switch g.player.class {
-				case "Lyricistes":
-					g.Inventaire.AddItem("Cristalline - mystérieuse")
-				case "Performeurs":
-					g.Inventaire.AddItem("Cristalline - tonic")
-				case "Hitmakers":
-					g.Inventaire.AddItem("Cristalline - suspicieuse")
-				default:
-					g.Inventaire.AddItem("Cristalline")
-				}
-			}
-		},
-	},
-
-	{
-		Name:  "Followers",
-		Price: 200,
-		Action: func(g *Game) {
-			g.Followers += 100
-		},
-	},
-}
-
 // -----------------
 // Notification system
 // -----------------
@@ -147,9 +116,11 @@ type Game struct {
 	bgmPlayer            *audio.Player
 	menuSelected         int
 	volume               int
-	moneyIcon            *ebiten.Image   // ✅ icône argent
-	followerIcon         *ebiten.Image   // ✅ icône followers
-	MerchantZone         image.Rectangle // Zone interaction marchand
+	reducedMotion        bool          // Mouvements réduits en combat (pas de tremblement ni de flash)
+	hardMode             bool          // Mode difficile : les ennemis anticipent
+	battleSpeed          BattleSpeed   // Vitesse des combats (1x, 2x, 4x, instantané)
+	moneyIcon            *ebiten.Image // ✅ icône argent
+	followerIcon         *ebiten.Image // ✅ icône followers
 	showBlacksmithMenu   bool
 	blacksmithSelected   int
 
@@ -164,6 +135,30 @@ type Game struct {
 	newSaveClass  string
 	cursorTimer   int
 	pendingDelete string // confirmation suppression
+	saveName      string // Nom de la sauvegarde en cours
+
+	// Replays
+	replays        []BattleRecord // Derniers combats enregistrés
+	replaySelected int            // Combat sélectionné dans la liste
+	replayBattle   *Battle        // Combat en cours de lecture
+
+	// Versus local
+	versusSaves  []Save  // Persos disponibles
+	versusPicks  [2]int  // Perso choisi par chaque joueur
+	versusStep   int     // Joueur en train de choisir (2 = les deux ont choisi)
+	versusBattle *Battle // Combat versus en cours
+
+	// Versus en réseau
+	netSaves    []Save             // Persos disponibles
+	netPick     int                // Perso choisi
+	netJoin     bool               // Rejoindre (true) ou héberger (false)
+	netAddr     string             // Adresse saisie (hôte:port)
+	netStatus   string             // Message d'état de la connexion
+	netPending  chan netResult     // Connexion en cours (nil sinon)
+	netListener net.Listener       // Port ouvert pendant l'attente d'un adversaire
+	netCancel   context.CancelFunc // Interrompt une connexion en cours
+	netSession  *NetSession        // Partie en cours
+	netBattle   *Battle            // Combat réseau en cours
 
 	// Gameplay
 	player                *Player
@@ -172,15 +167,28 @@ type Game struct {
 	inBattle              bool
 	currentEnemy          *Enemy
 	battle                *Battle
-	Merchant              *Merchant
+	Merchants             []*Merchant // Marchands de la map
+	Merchant              *Merchant   // Marchand dont le menu est ouvert
+	clock                 GameClock   // Heure du jeu
 	Money                 int
 	Followers             int
 	SelectedMerchantIndex int
+	merchantTab           int             // Onglet du marchand (MerchantTab...)
+	buyQty                int             // Quantité de l'article sélectionné à l'achat
+	sellConfirm           bool            // Vente en attente de confirmation
+	haggle                *haggleDialogue // Marchandage en cours (voir haggle.go)
+	buyback               []soldItem      // Objets vendus pendant la visite, rachetables
 	PlayerClass           string
 	Winner                string // "player" ou "enemy"
 
 	// Zone de combat
 	combatZone image.Rectangle
+	regenTimer int // Frames écoulées depuis le dernier point d'ego récupéré
+
+	// Résultats du dernier combat gagné
+	lastRewards     Rewards
+	lastPerformance BattlePerformance
+	lastEnemyName   string
 
 	// Inventaire
 	Inventaire *Inventaire
@@ -207,8 +215,11 @@ func LoadImage(path string) *ebiten.Image {
 // -----------------
 var prevKeyState = make(map[ebiten.Key]bool)
 
+// isKeyPressed lit l'état d'une touche (remplacé dans les tests pour simuler le clavier)
+var isKeyPressed = ebiten.IsKeyPressed
+
 func IsKeyJustPressed(key ebiten.Key) bool {
-	pressed := ebiten.IsKeyPressed(key)
+	pressed := isKeyPressed(key)
 	was := prevKeyState[key]
 	prevKeyState[key] = pressed
 	return pressed && !was
@@ -220,10 +231,16 @@ func IsKeyJustPressed(key ebiten.Key) bool {
 func NewGame() *Game {
 	g := &Game{
 		state:      StateIntro, // commence par l'intro
-		volume:     50,
 		introText:  "Bienvenue dans Rap Legacy !",
 		introTimer: 0,
 	}
+	// Réglages enregistrés (volume, mouvements réduits, difficulté, vitesse des combats)
+	settings, err := LoadSettings()
+	if err != nil {
+		log.Println("Erreur chargement réglages :", err)
+	}
+	g.applySettings(settings)
+
 	g.moneyIcon = LoadImage("assets/money.png")
 	g.followerIcon = LoadImage("assets/followers.png")
 
@@ -259,6 +276,18 @@ func NewGame() *Game {
 			Label:  "Quit",
 			Action: func() { os.Exit(0) },
 		},
+		{
+			Rect:      image.Rect(790, 800, 1040, 860),
+			Label:     "Versus",
+			Action:    func() { g.openVersus() },
+			ShowLabel: true,
+		},
+		{
+			Rect:      image.Rect(790, 880, 1040, 940),
+			Label:     "Réseau",
+			Action:    func() { g.openNetwork() },
+			ShowLabel: true,
+		},
 	}
 
 	// Charger la police externe
@@ -306,19 +335,11 @@ func NewGame() *Game {
 				g.bgmPlayer.Play() // lancer la musique
 			}
 		}
-	} // ✅ Ajout du marchand
-	g.Merchant = &Merchant{
-		X:      500,
-		Y:      750,
-		Items:  []string{"Cristaline", "Followers"},
-		Active: true,
-		Sprite: LoadImage("assets/marchand.png"),
-	}
-	g.MerchantZone = image.Rect(500, 700, 500+128, 750+128)
+	}
 	g.Followers = 0
-	g.Money = 100 // ton joueur commence avec 100 pièces
+	g.Money = startingMoney // ton joueur commence avec 100 pièces
 	g.Inventaire = &Inventaire{
-		Items: []string{},
+		Items: ItemStacks{},
 	}
 	// Charger le background
 	img, _, err := ebitenutil.NewImageFromFile("assets/background.png")
@@ -332,10 +353,15 @@ func NewGame() *Game {
 
 // Marchand représente un vendeur avec quelques items
 type Merchant struct {
+	ID     string // Identifiant (voir assets/merchants.json)
+	Name   string // Nom affiché sur la map
 	X, Y   float64
-	Items  []string
+	Shop   string // Catalogue vendu (voir assets/shops.json)
 	Active bool
 	Sprite *ebiten.Image
+
+	State MerchantState // Stock, vitrine et prochain réassort (enregistrés dans la save)
+	rng   *mrand.Rand   // Tirages de la vitrine
 }
 
 // Affiche le marchand (sprite PNG)
@@ -354,15 +380,17 @@ func (m *Merchant) Draw(screen *ebiten.Image) {
 // -----------------
 func (g *Game) Update() error {
 	UpdateNotifications()
-	// === Mise à jour de la zone du marchand pour coller au sprite ===
-	if g.Merchant != nil && g.Merchant.Sprite != nil {
-		w, h := g.Merchant.Sprite.Size() // largeur et hauteur du PNG du marchand
-		g.MerchantZone = image.Rect(
-			int(g.Merchant.X),
-			int(g.Merchant.Y),
-			int(g.Merchant.X)+w,
-			int(g.Merchant.Y)+h,
-		)
+	// Fermeture de la fenêtre : la partie en cours est enregistrée avant de quitter
+	if ebiten.IsWindowBeingClosed() {
+		g.saveProgress()
+		return ebiten.Termination
+	}
+	// Le temps ne passe que sur la map (pas pendant les combats) ; les marchands se réassortissent avec lui
+	if g.state == StatePlaying && !g.inBattle {
+		g.clock++
+		for _, m := range g.Merchants {
+			m.Update(g.clock)
+		}
 	}
 
 	switch g.state {
@@ -382,6 +410,14 @@ func (g *Game) Update() error {
 		g.updateMerchantMenu() // logique marchant uniquement dans Update
 	case StateBlacksmithMenu:
 		g.updateBlacksmithMenu()
+	case StateReplay:
+		g.updateReplay()
+	case StateBattleResults:
+		g.updateBattleResults()
+	case StateVersus:
+		g.updateVersus()
+	case StateNetwork:
+		g.updateNetwork()
 	}
 	return nil
 }
@@ -417,8 +453,8 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			for _, e := range g.enemies {
 				e.Draw(screen)
 			}
-			if g.Merchant != nil {
-				g.Merchant.Draw(screen)
+			for _, m := range g.Merchants {
+				m.Draw(screen)
 			}
 			if g.showBlacksmithMenu {
 				g.drawBlacksmithMenu(screen)
@@ -436,15 +472,22 @@ func (g *Game) Draw(screen *ebiten.Image) {
 				}
 			}
 
+			// Message repos au studio
+			if g.inRestZone() && g.player.Ego < g.player.Stats().Ego {
+				if g.fontSmall != nil {
+					text.Draw(screen, "Appuie sur E pour te reposer au studio", g.fontSmall, restZone.Min.X, restZone.Max.Y+20, color.White)
+				} else {
+					ebitenutil.DebugPrintAt(screen, "Appuie sur E pour te reposer au studio", restZone.Min.X, restZone.Max.Y+20)
+				}
+			}
+
 			// Message marchand au-dessus du marchand
-			if g.player != nil && g.Merchant != nil {
-				playerRect := image.Rect(int(g.player.X), int(g.player.Y), int(g.player.X)+32, int(g.player.Y)+32)
-				if g.MerchantZone.Overlaps(playerRect) {
-					if g.fontSmall != nil {
-						text.Draw(screen, "Appuie sur E pour parler au marchand", g.fontSmall, int(g.Merchant.X)-40, int(g.Merchant.Y)-20, color.White)
-					} else {
-						ebitenutil.DebugPrintAt(screen, "Appuie sur E pour parler au marchand", int(g.Merchant.X)-40, int(g.Merchant.Y)-20)
-					}
+			if m := g.merchantNear(); m != nil {
+				msg := "Appuie sur E pour parler à " + m.Name
+				if g.fontSmall != nil {
+					text.Draw(screen, msg, g.fontSmall, int(m.X)-40, int(m.Y)-20, color.White)
+				} else {
+					ebitenutil.DebugPrintAt(screen, msg, int(m.X)-40, int(m.Y)-20)
 				}
 			}
 
@@ -479,18 +522,38 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			if g.PlayerClass != "" {
 				text.Draw(screen, "Classe: "+g.PlayerClass, g.fontSmall, hudX, hudY+105, color.White)
 			}
+			if g.player != nil && !g.inBattle {
+				stats := g.player.Stats()
+				text.Draw(screen, fmt.Sprintf("Ego: %d/%d", g.player.Ego, stats.Ego), g.fontSmall, hudX, hudY+135, color.White)
+				text.Draw(screen, fmt.Sprintf("Flow: %d  Charisme: %d", stats.Flow, stats.Charisma), g.fontSmall, hudX, hudY+165, color.White)
+				text.Draw(screen, g.clock.String(), g.fontSmall, hudX, hudY+195, color.White)
+			}
 		} else {
 			ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d", g.Money), hudX+40, hudY+25)
 			ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d", g.Followers), hudX+40, hudY+65)
 			if g.PlayerClass != "" {
 				ebitenutil.DebugPrintAt(screen, "Classe: "+g.PlayerClass, hudX, hudY+105)
 			}
+			if g.player != nil && !g.inBattle {
+				stats := g.player.Stats()
+				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Ego: %d/%d", g.player.Ego, stats.Ego), hudX, hudY+135)
+				ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Flow: %d  Charisme: %d", stats.Flow, stats.Charisma), hudX, hudY+165)
+				ebitenutil.DebugPrintAt(screen, g.clock.String(), hudX, hudY+195)
+			}
 		}
 
 	case StateMerchantMenu:
 		g.drawMerchantMenu(screen)
 	case StateBlacksmithMenu:
 		g.drawBlacksmithMenu(screen)
+	case StateReplay:
+		g.drawReplay(screen)
+	case StateBattleResults:
+		g.drawBattleResults(screen)
+	case StateVersus:
+		g.drawVersus(screen)
+	case StateNetwork:
+		g.drawNetwork(screen)
 	}
 
 	// Notifications (dessinées par-dessus tout)
@@ -515,50 +578,19 @@ func (g *Game) drawMerchantMenu(screen *ebiten.Image) {
 		screen.Fill(color.RGBA{50, 30, 20, 255})
 	}
 
-	// --- Texte à l'intérieur du panneau ---
-	startX := 420 // un peu plus à gauche
-	startY := 400 // plus bas
-
-	if g.fontSmall != nil {
-		text.Draw(screen, "=== Marchand ===", g.fontSmall, startX, startY, color.White)
-		text.Draw(screen, "1. Cristalline - 50$", g.fontSmall, startX, startY+50, color.White)
-		text.Draw(screen, "2. Followers x100 - 200$", g.fontSmall, startX, startY+100, color.White)
-		text.Draw(screen, "Appuie sur ESC pour quitter", g.fontSmall, startX, startY+180, color.White)
-	} else {
-		ebitenutil.DebugPrintAt(screen, "=== Marchand ===", startX, startY)
-		ebitenutil.DebugPrintAt(screen, "1. Cristalline - 50$", startX, startY+50)
-		ebitenutil.DebugPrintAt(screen, "2. Followers x100 - 200$", startX, startY+100)
-		ebitenutil.DebugPrintAt(screen, "Appuie sur ESC pour quitter", startX, startY+180)
-	}
+	// --- Onglets et liste de l'onglet, à l'intérieur du panneau ---
+	g.drawMerchantTabs(screen)
 }
 
 // -----------------
 // Update Merchant Menu (logique)
 // -----------------
 func (g *Game) updateMerchantMenu() {
-	// Ici on n'utilise que inpututil.IsKeyJustPressed : unique déclenchement par touche
-	if inpututil.IsKeyJustPressed(ebiten.Key1) {
-		if g.Money >= MerchantItems[0].Price {
-			g.Money -= MerchantItems[0].Price
-			MerchantItems[0].Action(g)
-			AddNotification("Tu as acheté : " + MerchantItems[0].Name)
-		} else {
-			AddNotification("Pas assez d'argent !")
-		}
-	}
-
-	if inpututil.IsKeyJustPressed(ebiten.Key2) {
-		if g.Money >= MerchantItems[1].Price {
-			g.Money -= MerchantItems[1].Price
-			MerchantItems[1].Action(g)
-			AddNotification("Tu as acheté : " + MerchantItems[1].Name)
-		} else {
-			AddNotification("Pas assez d'argent !")
-		}
-	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-		g.state = StatePlaying
+	// Échap ferme d'abord la confirmation de vente ou le marchandage, puis le marchand
+	busy := g.sellConfirm || g.haggle != nil
+	g.updateMerchantTabs()
+	if !busy && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.closeMerchant()
 	}
 }
 
@@ -624,18 +656,13 @@ func (g *Game) updateBlacksmithMenu() {
 			// ----------------------
 			// Améliorer une Cristalline simple
 			// ----------------------
-			if inv.HasCristalline() && inv.RemoveCristalline() {
+			if inv.RemoveItem("cristalline") { // Seulement la version simple : les versions de classe sont déjà spéciales
 				// transforme selon la classe du joueur
-				switch g.PlayerClass {
-				case "Lyricistes":
-					inv.AddItem("Cristalline - mystérieuse")
-				case "Performeurs":
-					inv.AddItem("Cristalline - tonic")
-				case "Hitmakers":
-					inv.AddItem("Cristalline - suspicieuse")
-				default:
-					inv.AddItem("Cristalline - mystérieuse")
+				upgraded := "cristalline_mysterieuse"
+				if d, ok := ItemForClass("cristalline", g.PlayerClass); ok {
+					upgraded = d.ID
 				}
+				inv.AddItem(upgraded)
 				AddNotification("🔨 Le forgeron a transformé ta Cristalline en version spéciale !")
 			} else {
 				AddNotification("❌ Il te faut une Cristalline simple pour améliorer.")
@@ -645,18 +672,15 @@ func (g *Game) updateBlacksmithMenu() {
 			// ----------------------
 			// Fusion Cristalline (spéciale ou simple) + Téléphone => BIG Cristalline
 			// ----------------------
-			if inv.HasCristalline() && inv.HasItem("Téléphone") {
+			if inv.HasTag("upgradable") && inv.HasItem("telephone") {
 				// retire une cristalline (simple ou spéciale)
-				okCrist := inv.RemoveCristalline() ||
-					inv.RemoveItem("Cristalline - mystérieuse") ||
-					inv.RemoveItem("Cristalline - tonic") ||
-					inv.RemoveItem("Cristalline - suspicieuse")
+				_, okCrist := inv.RemoveTag("upgradable")
 
 				// retire un téléphone
-				okTel := inv.RemoveItem("Téléphone")
+				okTel := inv.RemoveItem("telephone")
 
 				if okCrist && okTel {
-					inv.AddItem("Cristalline - big")
+					inv.AddItem("cristalline_big")
 					AddNotification("💥 Fusion réussie ! Tu as créé une BIG Cristalline (+100 ego) !")
 				} else {
 					AddNotification("❌ Fusion échouée (objet manquant).")
@@ -666,13 +690,15 @@ func (g *Game) updateBlacksmithMenu() {
 			}
 		}
 
-		// revenir au jeu après action
+		// revenir au jeu après action (l'inventaire a changé : on enregistre)
 		g.state = StatePlaying
+		g.saveProgress()
 	}
 
 	// Quitter avec ESC
 	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
 		g.state = StatePlaying
+		g.saveProgress()
 	}
 }
 
@@ -739,6 +765,15 @@ func (g *Game) drawMenu(screen *ebiten.Image) {
 	}
 
 	for i, btn := range g.menuButtons {
+		if btn.ShowLabel {
+			x := btn.Rect.Min.X + 20
+			y := (btn.Rect.Min.Y+btn.Rect.Max.Y)/2 + 10
+			if g.fontBig != nil {
+				text.Draw(screen, btn.Label, g.fontBig, x, y, color.White)
+			} else {
+				ebitenutil.DebugPrintAt(screen, btn.Label, x, y)
+			}
+		}
 		if i == g.menuSelected {
 			x := btn.Rect.Min.X - 40
 			y := (btn.Rect.Min.Y+btn.Rect.Max.Y)/2 + 10
@@ -773,11 +808,45 @@ func (g *Game) updateSettings() {
 		g.bgmPlayer.SetVolume(float64(g.volume) / 100.0)
 	}
 
+	// Mouvements réduits : M pour activer / désactiver
+	if IsKeyJustPressed(ebiten.KeyM) {
+		g.reducedMotion = !g.reducedMotion
+	}
+	// Mode difficile : D pour activer / désactiver
+	if IsKeyJustPressed(ebiten.KeyD) {
+		g.hardMode = !g.hardMode
+	}
+	// Vitesse des combats : V pour passer à la suivante
+	if IsKeyJustPressed(ebiten.KeyV) {
+		g.battleSpeed = g.battleSpeed.Next()
+	}
+
 	if IsKeyJustPressed(ebiten.KeyEscape) {
+		if err := SaveSettings(g.currentSettings()); err != nil {
+			log.Println("Erreur sauvegarde réglages :", err)
+		}
 		g.state = StateMenu
 	}
 }
 
+// currentSettings regroupe les réglages du jeu pour les enregistrer
+func (g *Game) currentSettings() Settings {
+	return Settings{
+		Volume:        g.volume,
+		ReducedMotion: g.reducedMotion,
+		HardMode:      g.hardMode,
+		BattleSpeed:   g.battleSpeed,
+	}
+}
+
+// applySettings applique des réglages chargés au jeu
+func (g *Game) applySettings(s Settings) {
+	g.volume = s.Volume
+	g.reducedMotion = s.ReducedMotion
+	g.hardMode = s.HardMode
+	g.battleSpeed = s.BattleSpeed
+}
+
 func (g *Game) drawSettings(screen *ebiten.Image) {
 	if g.settingsBg != nil {
 		opts := &ebiten.DrawImageOptions{}
@@ -789,6 +858,15 @@ func (g *Game) drawSettings(screen *ebiten.Image) {
 	w, h := screen.Size()
 	title := "SETTINGS"
 	vol := fmt.Sprintf("Volume: %d", g.volume)
+	motion := "Mouvements réduits [M]: non"
+	if g.reducedMotion {
+		motion = "Mouvements réduits [M]: oui"
+	}
+	hard := "Mode difficile [D]: non"
+	if g.hardMode {
+		hard = "Mode difficile [D]: oui"
+	}
+	speed := "Vitesse des combats [V]: " + g.battleSpeed.String()
 	info := "Press ESC to return"
 
 	if g.fontBig != nil {
@@ -799,9 +877,15 @@ func (g *Game) drawSettings(screen *ebiten.Image) {
 		ebitenutil.DebugPrintAt(screen, vol, w/2-60, h/2)
 	}
 	if g.fontSmall != nil {
-		text.Draw(screen, info, g.fontSmall, w/2-(len(info)*9), h/2+100, color.RGBA{200, 200, 200, 255})
+		text.Draw(screen, motion, g.fontSmall, w/2-(len(motion)*7), h/2+60, color.White)
+		text.Draw(screen, hard, g.fontSmall, w/2-(len(hard)*7), h/2+90, color.White)
+		text.Draw(screen, speed, g.fontSmall, w/2-(len(speed)*7), h/2+120, color.White)
+		text.Draw(screen, info, g.fontSmall, w/2-(len(info)*9), h/2+160, color.RGBA{200, 200, 200, 255})
 	} else {
-		ebitenutil.DebugPrintAt(screen, info, w/2-80, h/2+100)
+		ebitenutil.DebugPrintAt(screen, motion, w/2-80, h/2+60)
+		ebitenutil.DebugPrintAt(screen, hard, w/2-80, h/2+90)
+		ebitenutil.DebugPrintAt(screen, speed, w/2-80, h/2+120)
+		ebitenutil.DebugPrintAt(screen, info, w/2-80, h/2+160)
 	}
 }
 
@@ -1036,6 +1120,51 @@ func (g *Game) drawCreateSave(screen *ebiten.Image) {
 	} else {
 		ebitenutil.DebugPrintAt(screen, "Appuie sur ECHAP pour annuler", 600, 640)
 	}
+
+	// --- Passif et attaque signature de la classe choisie ---
+	if class, ok := ClassFor(g.newSaveClass); ok {
+		passive := "Passif : " + class.Passive.Name + " - " + class.Passive.Desc
+		signature := "Signature : " + moveDefs()[class.Signature].Name
+		if g.fontSmall != nil {
+			text.Draw(screen, passive, g.fontSmall, 600, 720, color.RGBA{255, 215, 0, 255})
+			text.Draw(screen, signature, g.fontSmall, 600, 760, color.RGBA{255, 215, 0, 255})
+		} else {
+			ebitenutil.DebugPrintAt(screen, passive, 600, 720)
+			ebitenutil.DebugPrintAt(screen, signature, 600, 760)
+		}
+	}
+}
+
+// -----------------
+// Save progress
+// -----------------
+
+// saveProgress enregistre l'état du joueur (stats, position, argent, followers, inventaire, équipement), l'heure et
+// les marchands dans la save en cours
+func (g *Game) saveProgress() {
+	if g.saveName == "" || g.player == nil {
+		return
+	}
+	s, ok, err := GetSave(g.saveName)
+	if err != nil || !ok {
+		log.Println("Impossible de retrouver la sauvegarde :", g.saveName, err)
+		return
+	}
+	s.PlayerX, s.PlayerY = g.player.X, g.player.Y
+	s.Ego, s.Flow, s.Charisma = g.player.Ego, g.player.Flow, g.player.Charisma
+	s.Money, s.Followers = g.Money, g.Followers
+	s.Equipment = g.player.Equipment
+	s.Clock = g.clock
+	s.Merchants = map[string]MerchantState{}
+	for _, m := range g.Merchants {
+		s.Merchants[m.ID] = m.State
+	}
+	if g.Inventaire != nil {
+		s.Inventory = g.Inventaire.Items
+	}
+	if err := OverwriteSave(s); err != nil {
+		log.Println("Impossible d'enregistrer la partie :", err)
+	}
 }
 
 // -----------------
@@ -1046,9 +1175,15 @@ func (g *Game) startGameFromSave(s Save) {
 	g.player.Ego = s.Ego
 	g.player.Flow = s.Flow
 	g.player.Charisma = s.Charisma
+	g.player.Equipment = s.Equipment
 	g.Inventaire = NewInventaireFromItems(s.Inventory)
+	g.Money = s.Money
+	g.Followers = s.Followers
 
 	g.PlayerClass = s.Class
+	g.saveName = s.Name
+	g.clock = s.Clock
+	g.spawnMerchants(s.Merchants)
 
 	g.mapData = NewMap()
 
@@ -1073,10 +1208,25 @@ func (g *Game) startGameFromSave(s Save) {
 	g.state = StatePlaying
 }
 
+// configureBattle applique les polices et les réglages du jeu à un combat
+func (g *Game) configureBattle(b *Battle) *Battle {
+	b.SetFonts(g.fontSmall, g.fontBig)
+	b.SetReducedMotion(g.reducedMotion)
+	b.SetSpeed(g.battleSpeed)
+	return b
+}
+
 // -----------------
 // Playing update
 // -----------------
 func (g *Game) updatePlaying() {
+	// Combat en cours : il garde le clavier pour lui (le freestyle lit le texte tapé, qui ne doit ni ouvrir le
+	// forgeron ni déplacer le joueur sur la map)
+	if g.inBattle && g.battle != nil {
+		g.updateBattle()
+		return
+	}
+
 	// Player update (sans param — ta Player.Update n'attend pas mapData)
 	if g.player != nil {
 		g.player.Update()
@@ -1092,121 +1242,86 @@ func (g *Game) updatePlaying() {
 		g.blacksmithSelected = 0
 		return
 	}
+	// Revoir les derniers combats avec R
+	if IsKeyJustPressed(ebiten.KeyR) && !g.inBattle {
+		g.openReplays()
+		return
+	}
 
-	// Si inventaire ouvert → navigation + actions
-	if g.Inventaire != nil && g.Inventaire.Open {
-		// navigation Up/Down
-		if IsKeyJustPressed(ebiten.KeyUp) {
-			g.Inventaire.selected--
-			if g.Inventaire.selected < 0 && len(g.Inventaire.Items) > 0 {
-				g.Inventaire.selected = len(g.Inventaire.Items) - 1
-			}
-		}
-		if IsKeyJustPressed(ebiten.KeyDown) {
-			g.Inventaire.selected++
-			if g.Inventaire.selected >= len(g.Inventaire.Items) && len(g.Inventaire.Items) > 0 {
-				g.Inventaire.selected = 0
-			}
-		}
-
-		// Consommation : Enter
-		if IsKeyJustPressed(ebiten.KeyEnter) && len(g.Inventaire.Items) > 0 {
-			idx := g.Inventaire.selected
-			if idx >= 0 && idx < len(g.Inventaire.Items) {
-				item := g.Inventaire.Items[idx]
-
-				switch item {
-				// Cristalline
-				case "Cristalline - mystérieuse", "Cristalline - tonic", "Cristalline - suspicieuse":
-					if g.player != nil {
-						g.player.BonusEgo += 50
-						AddNotification(fmt.Sprintf("%s consommée : +50 Ego au prochain combat", item))
-					}
-
-				// Micro
-				case "Micro":
-					if g.player != nil {
-						g.player.BonusEgo += 10
-						AddNotification(" Micro utilisé : +10 Ego pour le prochain combat")
-					}
-
-				// Cigarette électronique
-				case "Cigarette électronique":
-					if g.player != nil {
-						g.player.PendingEnemyEgoDebuff += 15
-						AddNotification(" Cigarette électronique utilisée : l'ennemi perd 15 Ego au prochain combat")
-					}
-					// Micro
-				case "Cristalline - big":
-					if g.player != nil {
-						g.player.BonusEgo += 100
-						AddNotification(" Cristalline - big utilisé : +100 Ego pour le prochain combat")
-					}
-
-				// Autres objets
-				default:
-					AddNotification("Tu as choisi : " + item)
-				}
+	// Si inventaire ouvert → navigation + utilisation des objets
+	if g.Inventaire != nil {
+		g.Inventaire.Update(g.player)
+	}
 
-				// 🔥 Suppression de l’objet consommé (commun à tous les cas)
-				g.Inventaire.Items = append(g.Inventaire.Items[:idx], g.Inventaire.Items[idx+1:]...)
-				if g.Inventaire.Icons != nil {
-					delete(g.Inventaire.Icons, item)
-				}
-				if idx >= len(g.Inventaire.Items) {
-					g.Inventaire.selected = len(g.Inventaire.Items) - 1
-				}
-				if g.Inventaire.selected < 0 {
-					g.Inventaire.selected = 0
-				}
-			}
-		}
+	// Récupération de l'ego hors combat (temps + repos au studio)
+	g.updateEgoRegen()
+	if !g.inBattle && g.inRestZone() && IsKeyJustPressed(ebiten.KeyE) {
+		g.rest()
 	}
 
 	// Détection entrée zone marchand + E pour ouvrir le menu
-	if g.player != nil {
-		playerRect := image.Rect(
-			int(g.player.X), int(g.player.Y),
-			int(g.player.X)+32, int(g.player.Y)+32, // adapte à la taille du sprite joueur
-		)
-
-		if g.MerchantZone.Overlaps(playerRect) {
-			// Si touche E pressée → ouvre le menu du marchand
-			if IsKeyJustPressed(ebiten.KeyE) {
-				g.state = StateMerchantMenu
-			}
+	if m := g.merchantNear(); m != nil {
+		// Si touche E pressée → ouvre le menu du marchand
+		if IsKeyJustPressed(ebiten.KeyE) {
+			g.openMerchant(m)
 		}
 	}
 
 	// Détection entrée zone combat + E pour lancer combat
 	if g.player != nil && g.combatZone.Overlaps(image.Rect(int(g.player.X), int(g.player.Y), int(g.player.X)+32, int(g.player.Y)+32)) && !g.inBattle {
 		if IsKeyJustPressed(ebiten.KeyE) {
-			g.inBattle = true
-			if len(g.enemies) > 0 {
+			if g.player.IsKO() {
+				AddNotification("Ton ego est à zéro, repose-toi avant de clasher !")
+			} else if len(g.enemies) > 0 {
+				g.inBattle = true
 				// On passe BonusEgo à NewBattle via g.player
-				g.battle = NewBattle(g.player, g.enemies[0])
+				g.battle = g.configureBattle(NewBattle(g.player, g.enemies[0]))
+				if g.Inventaire != nil {
+					g.battle.SetItems(SidePlayer, g.Inventaire.Items.IDs())
+				}
+				if g.hardMode {
+					g.battle.UseLookaheadAI()
+				}
 			}
 		}
 	}
+}
 
-	// Si en bataille → update combat
-	if g.inBattle && g.battle != nil {
-		g.battle.Update()
-		if g.battle.IsOver() {
-			// 👉 Vérifie si le joueur a gagné
-			if g.battle.Winner == "player" {
-				g.Money += 50
-				g.Followers += 100
-				AddNotification("Victoire ! +50$ et +100 followers ")
-			} else if g.battle.Winner == "enemy" {
-				AddNotification("Défaite... ")
+// updateBattle fait avancer le combat en cours et applique son résultat quand il se termine
+func (g *Game) updateBattle() {
+	g.battle.Update()
+	if g.battle.IsOver() {
+		// 👉 Vérifie si le joueur a gagné : butin selon l'ennemi et la performance
+		if g.battle.Winner == "player" {
+			enemyName := g.battle.combat.Fighter(SideEnemy).Name
+			perf := PerformanceOf(g.battle)
+			rw := RollRewards(LootTableFor(enemyName), perf, g.battle.combat.Rand())
+			if class, ok := ClassFor(g.PlayerClass); ok {
+				rw.Followers += rw.Followers * class.Passive.FollowerBonus / 100 // Passif des Hitmakers
 			}
+			g.grantRewards(enemyName, perf, rw)
+		}
 
-			// Reset état après combat
-			g.inBattle = false
-			g.battle = nil
-			g.player.BonusEgo = 0 // le bonus ne s’applique qu’une fois
+		// L'ego perdu en combat reste perdu sur la map
+		if g.player != nil {
+			g.player.Ego = PostBattleEgo(g.player.Ego, g.battle.combat.Fighter(SidePlayer).Ego)
 		}
+		if g.battle.Winner == "enemy" {
+			g.applyDefeat()
+		}
+
+		// Enregistre le combat dans le journal de la sauvegarde
+		if g.saveName != "" {
+			if err := AppendBattleRecord(g.saveName, NewBattleRecord(g.battle)); err != nil {
+				log.Println("Erreur enregistrement combat:", err)
+			}
+		}
+		g.saveProgress()
+
+		// Reset état après combat
+		g.inBattle = false
+		g.battle = nil
+		g.player.BonusEgo = 0 // le bonus ne s’applique qu’une fois
 	}
 }
 
@@ -0,0 +1,60 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fakeKeyboard remplace le clavier par les touches enfoncées de la map retournée
+func fakeKeyboard(t *testing.T) map[ebiten.Key]bool {
+	pressed := map[ebiten.Key]bool{}
+	isKeyPressed = func(k ebiten.Key) bool { return pressed[k] }
+	t.Cleanup(func() {
+		isKeyPressed = ebiten.IsKeyPressed
+		clear(prevKeyState)
+	})
+	return pressed
+}
+
+// tap appuie sur une touche pendant un tick puis la relâche pendant le suivant
+func tap(g *Game, pressed map[ebiten.Key]bool, key ebiten.Key) {
+	pressed[key] = true
+	g.updatePlaying()
+	pressed[key] = false
+	g.updatePlaying()
+}
+
+func TestFreestyleKeysStayInBattle(t *testing.T) {
+	t.Chdir("..")
+	pressed := fakeKeyboard(t)
+	g := &Game{state: StatePlaying, player: NewPlayer(0, 0, "Lyricistes"), Inventaire: NewInventaire(), inBattle: true}
+	g.battle = NewBattle(g.player, &Enemy{Name: "Rival Rapper", Ego: 100, Flow: 5})
+	g.battle.startFreestyle(SidePlayer)
+	x, y := g.player.X, g.player.Y
+
+	// Une rime tapée au clavier : "f" (forgeron), Tab (inventaire), R (replays), W/A/S/D (déplacement)
+	for _, key := range []ebiten.Key{ebiten.KeyF, ebiten.KeyTab, ebiten.KeyR, ebiten.KeyW, ebiten.KeyA, ebiten.KeyS, ebiten.KeyD} {
+		tap(g, pressed, key)
+	}
+
+	if g.state != StatePlaying {
+		t.Errorf("état %v pendant le freestyle", g.state)
+	}
+	if g.battle == nil || g.battle.State() != BattleFreestyle {
+		t.Fatal("le freestyle a été interrompu")
+	}
+	if g.Inventaire.Open {
+		t.Error("inventaire ouvert pendant le combat")
+	}
+	if g.player.X != x || g.player.Y != y {
+		t.Errorf("joueur déplacé sur la map : (%v, %v) -> (%v, %v)", x, y, g.player.X, g.player.Y)
+	}
+
+	// Hors combat, F ouvre bien le forgeron
+	g.inBattle, g.battle = false, nil
+	tap(g, pressed, ebiten.KeyF)
+	if g.state != StateBlacksmithMenu {
+		t.Errorf("état %v après F sur la map", g.state)
+	}
+}
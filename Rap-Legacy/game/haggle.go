@@ -0,0 +1,139 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"fmt"         // Répliques du marchand
+	"image/color" // Couleurs du dialogue
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// -----------------
+// Marchandage
+// -----------------
+
+// Chance de réussite en plus par point de charisme au-dessus de charismaBase (en %)
+const haggleCharismaChance = 3
+
+// Chance de réussite minimale et maximale (en %)
+const (
+	haggleMinChance = 5
+	haggleMaxChance = 95
+)
+
+// Répliques possibles : plus la remise demandée est grosse, moins le marchand cède
+var haggleOptions = []struct {
+	Line     string // Réplique du joueur
+	Discount int    // Remise demandée (en %)
+	Chance   int    // Chance de réussite de base (en %)
+}{
+	{"Un petit geste pour un habitué ?", 10, 70},
+	{"Fais-moi un vrai prix.", 25, 40},
+	{"C'est du vol, ton truc !", 40, 15},
+}
+
+// haggleDialogue est un marchandage en cours sur un article
+type haggleDialogue struct {
+	entry    int    // Article marchandé
+	selected int    // Réplique sélectionnée
+	result   string // Réponse du marchand ("" tant que le joueur n'a pas parlé)
+	success  bool   // Le marchand a cédé
+}
+
+// openHaggle commence un marchandage sur l'article sélectionné, si le marchand veut bien discuter
+func (g *Game) openHaggle() {
+	m := g.Merchant
+	i := g.SelectedMerchantIndex
+	if m == nil || i >= len(m.Catalog().Entries) {
+		return
+	}
+	switch {
+	case m.State.Angry:
+		AddNotification("Le marchand ne veut plus rien entendre aujourd'hui.")
+	case m.State.Haggled[m.Catalog().Entries[i].Key()] > 0:
+		AddNotification("Tu as déjà négocié cet article aujourd'hui.")
+	default:
+		g.haggle = &haggleDialogue{entry: i}
+	}
+}
+
+// haggleChance retourne la chance de réussite d'une réplique, charisme compris
+func (g *Game) haggleChance(option int) int {
+	chance := haggleOptions[option].Chance
+	if g.player != nil {
+		chance += (g.player.Stats().Charisma - charismaBase) * haggleCharismaChance
+	}
+	return max(min(chance, haggleMaxChance), haggleMinChance)
+}
+
+// updateHaggle : Haut/Bas ou clic choisit la réplique, Entrée la dit, Échap renonce. Une fois la réponse donnée,
+// n'importe laquelle de ces touches referme le dialogue.
+func (g *Game) updateHaggle() {
+	h := g.haggle
+	if h.result != "" {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) ||
+			inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.haggle = nil
+		}
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.haggle = nil
+		return
+	}
+
+	// La liste des répliques se parcourt comme celles du marchand
+	sel := g.SelectedMerchantIndex
+	g.SelectedMerchantIndex = h.selected
+	say := g.updateMerchantList(len(haggleOptions))
+	h.selected, g.SelectedMerchantIndex = g.SelectedMerchantIndex, sel
+	if !say {
+		return
+	}
+
+	m := g.Merchant
+	key := m.Catalog().Entries[h.entry].Key()
+	opt := haggleOptions[h.selected]
+	if m.rng.IntN(100) < g.haggleChance(h.selected) {
+		if m.State.Haggled == nil {
+			m.State.Haggled = map[string]int{}
+		}
+		m.State.Haggled[key] = opt.Discount
+		h.success = true
+		h.result = fmt.Sprintf("Bon... va pour -%d%%, mais c'est bien parce que c'est toi.", opt.Discount)
+	} else {
+		m.State.Angry = true
+		h.result = fmt.Sprintf("Tu te moques de moi ?! Aujourd'hui, c'est plein tarif (+%d%%).", angryPercent)
+	}
+}
+
+// drawHaggle dessine le marchandage à la place de la liste des articles
+func (g *Game) drawHaggle(screen *ebiten.Image, face font.Face, x, y int) {
+	h := g.haggle
+	m := g.Merchant
+	it := m.EntryItem(h.entry, g.PlayerClass)
+	label := m.Catalog().Entries[h.entry].Label(it)
+	text.Draw(screen, fmt.Sprintf("=== Marchander : %s (%d$) ===", label, g.buyPrice(h.entry, it)), face, x, y-25, color.White)
+
+	if h.result != "" {
+		col := color.Color(color.RGBA{120, 220, 120, 255})
+		if !h.success {
+			col = tooExpensiveColor
+		}
+		text.Draw(screen, m.Name+" : "+h.result, face, x, y+10, col)
+		return
+	}
+	text.Draw(screen, m.Name+" : Tu veux discuter le prix ? Vas-y, je t'écoute.", face, x, y+10, color.RGBA{255, 200, 80, 255})
+
+	// Les répliques occupent les lignes de la liste (mêmes zones cliquables)
+	var lines []merchantLine
+	for _, opt := range haggleOptions {
+		lines = append(lines, merchantLine{fmt.Sprintf("%s (-%d%%)", opt.Line, opt.Discount), color.White})
+	}
+	sel := g.SelectedMerchantIndex
+	g.SelectedMerchantIndex = h.selected
+	g.drawMerchantList(screen, face, x, y, lines)
+	g.SelectedMerchantIndex = sel
+}
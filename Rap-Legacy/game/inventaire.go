@@ -1,111 +1,297 @@
 package game
 
 import (
+	"encoding/json"
+	"fmt"
+	"image"
 	"image/color"
+	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
 )
 
+// -----------------
+// Piles d'objets
+// -----------------
+
+// ItemStack est une pile d'exemplaires identiques (un exemplaire tiré au sort reste seul dans sa pile)
+type ItemStack struct {
+	Item      // Exemplaire empilé (identifiant, rareté, propriétés)
+	Count int `json:"count"` // Quantité, au plus la taille de pile de l'objet
+}
+
+// ItemStacks est une liste de piles, telle qu'enregistrée dans les saves
+type ItemStacks []ItemStack
+
+// NewItemStacks range des objets (identifiants ou noms, un par exemplaire) en piles
+func NewItemStacks(items ...string) ItemStacks {
+	var s ItemStacks
+	for _, item := range items {
+		s = s.Add(item, 1)
+	}
+	return s
+}
+
+// Add ajoute n exemplaires communs d'un objet
+func (s ItemStacks) Add(item string, n int) ItemStacks {
+	return s.AddItem(Item{ID: item}, n)
+}
+
+// AddItem ajoute n exemplaires : complète d'abord les piles entamées, puis en ouvre de nouvelles.
+// Un exemplaire tiré au sort a toujours sa propre pile.
+func (s ItemStacks) AddItem(it Item, n int) ItemStacks {
+	it.ID = ItemID(it.ID)
+	size := ItemStackSize(it.ID)
+	if it.Unique() {
+		size = 1
+	}
+	for i := range s {
+		if n <= 0 {
+			return s
+		}
+		if s[i].ID == it.ID && !s[i].Unique() && !it.Unique() && s[i].Count < size {
+			added := min(size-s[i].Count, n)
+			s[i].Count += added
+			n -= added
+		}
+	}
+	for n > 0 {
+		added := min(size, n)
+		s = append(s, ItemStack{Item: it, Count: added})
+		n -= added
+	}
+	return s
+}
+
+// IDs retourne l'identifiant de chaque sorte d'objet, une seule fois
+func (s ItemStacks) IDs() []string {
+	var ids []string
+	for _, st := range s {
+		if !contains(ids, st.ID) {
+			ids = append(ids, st.ID)
+		}
+	}
+	return ids
+}
+
+// Compact regroupe les piles entamées d'un même objet, dans l'ordre de leur première apparition
+func (s ItemStacks) Compact() ItemStacks {
+	var out ItemStacks
+	for _, st := range s {
+		out = out.AddItem(st.Item, st.Count)
+	}
+	return out
+}
+
+// UnmarshalJSON lit les piles. Les anciennes saves enregistrent une liste de noms
+// (un par exemplaire) : ils sont regroupés en piles au chargement.
+func (s *ItemStacks) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var stacks ItemStacks
+	for _, r := range raw {
+		var name string
+		if json.Unmarshal(r, &name) == nil {
+			stacks = stacks.Add(name, 1)
+			continue
+		}
+		var st ItemStack
+		if err := json.Unmarshal(r, &st); err != nil {
+			return err
+		}
+		// Repasse par AddItem : une pile trop grande (taille réduite dans items.json) est redécoupée
+		stacks = stacks.AddItem(st.Item, st.Count)
+	}
+	*s = stacks
+	return nil
+}
+
 // -----------------
 // Inventaire struct
 // -----------------
 type Inventaire struct {
 	Open     bool
 	Bg       *ebiten.Image
-	Items    []string
-	Icons    map[string]*ebiten.Image
-	selected int
+	Items    ItemStacks // Piles d'objets, dans l'ordre de la grille
+	selected int        // Case sélectionnée (index parmi les piles affichées)
+
+	equipFocus   bool // Sélection dans le panneau d'équipement plutôt que dans la grille
+	slotSelected int  // Emplacement sélectionné (index dans equipSlots)
+
+	filter    int         // Filtre de catégorie (index dans inventoryFilters)
+	scroll    int         // Première ligne affichée de la grille
+	drag      itemDrag    // Objet tiré à la souris
+	mouseMode bool        // Dernière action à la souris : l'infobulle suit le curseur
+	lastMouse image.Point // Position du curseur à la frame précédente
+}
+
+// itemDrag est un objet en cours de glisser-déposer
+type itemDrag struct {
+	active bool
+	from   int    // Index de la pile tirée dans Items (-1 : objet porté)
+	slot   string // Emplacement d'origine d'un objet porté
+	id     string // Objet tiré
 }
 
 // -----------------
 // NewInventaire
 // -----------------
 func NewInventaire() *Inventaire {
-	// Liste des objets par défaut
-	objets := map[string]string{
-		"Téléphone":                 "assets/téléphone.png",
-		"RandM - 9000K":             "assets/puff.png",
-		"Cristalline - mystérieuse": "assets/cristalline.png",
-		"Cristalline - big":         "assets/cristalline_big.png",
-	}
-
-	items := []string{}
-	icons := map[string]*ebiten.Image{}
-
-	for name, path := range objets {
-		img := LoadImage(path)
-		items = append(items, name)
-		icons[name] = img
-	}
+	// Objets par défaut
+	return NewInventaireFromItems(NewItemStacks("telephone", "randm", "cristalline_mysterieuse", "cristalline_big"))
+}
 
+// -----------------
+// NewInventaireFromItems
+// -----------------
+func NewInventaireFromItems(items ItemStacks) *Inventaire {
 	return &Inventaire{
 		Open:  false,
 		Bg:    LoadImage("assets/inventaire.png"),
 		Items: items,
-		Icons: icons,
 	}
 }
 
 // -----------------
-// NewInventaireFromItems
+// AddItem
 // -----------------
-func NewInventaireFromItems(items []string) *Inventaire {
-	icons := map[string]*ebiten.Image{}
+func (inv *Inventaire) AddItem(item string) {
+	inv.Items = inv.Items.Add(item, 1)
+}
 
-	paths := map[string]string{
-		"Micro":                     "assets/micro.png",
-		"Cigarette électronique":    "assets/puff.png",
-		"Cigarette Electronique":    "assets/puff.png",
-		"Cristalline - mystérieuse": "assets/cristalline.png",
-		"Cristalline - tonic":       "assets/cristalline_tonic.png",
-		"Cristalline - suspicieuse": "assets/cristalline_suspicieuse.png",
-		"Cristalline - big":         "assets/cristalline_big.png",
-		"Téléphone":                 "assets/téléphone.png",
-		"RandM - 9000K":             "assets/puff.png",
-	}
+// Add range un exemplaire (avec sa rareté et ses propriétés)
+func (inv *Inventaire) Add(it Item) {
+	inv.Items = inv.Items.AddItem(it, 1)
+}
 
-	for _, item := range items {
-		if path, ok := paths[item]; ok {
-			icons[item] = LoadImage(path)
-		} else {
-			icons[item] = nil
+// -----------------
+// Disposition de la grille
+// -----------------
+
+const (
+	gridX, gridY = 240, 300 // Coin haut gauche de la grille
+	gridCols     = 7        // Cases par ligne
+	gridRows     = 5        // Lignes visibles (la grille défile au-delà)
+	gridCell     = 110      // Pas entre deux cases
+	gridCellSize = 100      // Taille d'une case
+	gridIconSize = 76       // Taille des icônes dans les cases
+
+	equipX, equipY = 1320, 300 // Panneau d'équipement
+	equipStep      = 130       // Pas entre deux emplacements
+)
+
+// Filtres de la grille, par catégorie ("" : tout)
+var inventoryFilters = []struct{ Category, Label string }{
+	{"", "Tout"},
+	{CategoryConsumable, "Consommables"},
+	{CategoryEquipment, "Équipement"},
+	{CategoryMaterial, "Matériaux"},
+}
+
+// Tris proposés sous les filtres
+var inventorySorts = []struct {
+	Label string
+	Less  func(a, b ItemDef) bool
+}{
+	{"Trier : nom", func(a, b ItemDef) bool { return a.Name < b.Name }},
+	{"Trier : catégorie", func(a, b ItemDef) bool {
+		if a.Category != b.Category {
+			return categoryRank(a.Category) < categoryRank(b.Category)
+		}
+		return a.Name < b.Name
+	}},
+}
+
+// categoryRank retourne la position d'une catégorie dans les filtres (ordre du tri par catégorie)
+func categoryRank(category string) int {
+	for i, f := range inventoryFilters {
+		if f.Category == category {
+			return i
 		}
 	}
+	return len(inventoryFilters)
+}
 
-	return &Inventaire{
-		Open:  false,
-		Bg:    LoadImage("assets/inventaire.png"),
-		Items: items,
-		Icons: icons,
+// Zones cliquables
+func filterRect(i int) image.Rectangle {
+	x := gridX + i*200
+	return image.Rect(x, gridY-110, x+190, gridY-70)
+}
+
+func sortRect(i int) image.Rectangle {
+	x := gridX + i*280
+	return image.Rect(x, gridY-60, x+270, gridY-20)
+}
+
+// cellRect retourne la case d'une position de la grille (index parmi les piles affichées)
+func cellRect(pos, scroll int) image.Rectangle {
+	x := gridX + pos%gridCols*gridCell
+	y := gridY + (pos/gridCols-scroll)*gridCell
+	return image.Rect(x, y, x+gridCellSize, y+gridCellSize)
+}
+
+// slotRect retourne la case d'un emplacement d'équipement
+func slotRect(i int) image.Rectangle {
+	y := equipY + i*equipStep
+	return image.Rect(equipX, y, equipX+gridCellSize, y+gridCellSize)
+}
+
+// cellAt retourne la position de la grille sous le curseur, -1 hors de la grille
+func (inv *Inventaire) cellAt(p image.Point) int {
+	area := image.Rect(gridX, gridY, gridX+gridCols*gridCell, gridY+gridRows*gridCell)
+	if !p.In(area) {
+		return -1
 	}
+	return ((p.Y-gridY)/gridCell+inv.scroll)*gridCols + (p.X-gridX)/gridCell
 }
 
-// -----------------
-// AddItem
-// -----------------
-func (inv *Inventaire) AddItem(item string) {
-	inv.Items = append(inv.Items, item)
-	paths := map[string]string{
-		"Micro":                     "assets/micro.png",
-		"Cigarette électronique":    "assets/puff.png",
-		"Cigarette Electronique":    "assets/puff.png",
-		"Cristalline - mystérieuse": "assets/cristalline.png",
-		"Cristalline - tonic":       "assets/cristalline_tonic.png",
-		"Cristalline - suspicieuse": "assets/cristalline_suspicieuse.png",
-		"Cristalline - big":         "assets/cristalline_big.png",
-		"Téléphone":                 "assets/téléphone.png",
-		"RandM - 9000K":             "assets/puff.png",
+// slotAt retourne l'emplacement d'équipement sous le curseur (index dans equipSlots), -1 sinon
+func slotAt(p image.Point) int {
+	for i := range equipSlots {
+		if p.In(slotRect(i)) {
+			return i
+		}
 	}
+	return -1
+}
 
-	if inv.Icons == nil {
-		inv.Icons = map[string]*ebiten.Image{}
+// visible retourne les index dans Items des piles qui passent le filtre, dans l'ordre de la grille
+func (inv *Inventaire) visible() []int {
+	category := inventoryFilters[inv.filter].Category
+	var idx []int
+	for i, st := range inv.Items {
+		if d, _ := LookupItem(st.ID); category == "" || d.Category == category {
+			idx = append(idx, i)
+		}
 	}
-	if path, ok := paths[item]; ok {
-		inv.Icons[item] = LoadImage(path)
+	return idx
+}
+
+// maxScroll retourne la dernière ligne de départ possible pour n piles
+func maxScroll(n int) int {
+	rows := (n + gridCols - 1) / gridCols
+	return max(rows-gridRows, 0)
+}
+
+// clampSelection garde la sélection et le défilement dans la grille
+func (inv *Inventaire) clampSelection() {
+	n := len(inv.visible())
+	inv.selected = max(min(inv.selected, n-1), 0)
+	// Au clavier, la grille défile pour garder la case sélectionnée visible
+	if !inv.mouseMode {
+		row := inv.selected / gridCols
+		inv.scroll = min(inv.scroll, row)
+		inv.scroll = max(inv.scroll, row-gridRows+1)
 	}
+	inv.scroll = max(min(inv.scroll, maxScroll(n)), 0)
 }
 
 // -----------------
@@ -113,63 +299,251 @@ func (inv *Inventaire) AddItem(item string) {
 // -----------------
 func (inv *Inventaire) Update(player *Player) {
 	if !inv.Open {
+		inv.drag = itemDrag{}
 		return
 	}
 
-	// Navigation
-	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) && inv.selected > 0 {
+	inv.updateMouse(player)
+	if inv.equipFocus {
+		inv.updateEquipment(player)
+	} else {
+		inv.updateGrid(player)
+	}
+	inv.clampSelection()
+}
+
+// updateGrid gère la grille au clavier : flèches pour se déplacer, Entrée pour utiliser ou équiper
+func (inv *Inventaire) updateGrid(player *Player) {
+	vis := inv.visible()
+	if IsKeyJustPressed(ebiten.KeyRight) {
+		inv.mouseMode = false
+		// Depuis le bord droit, on passe au panneau d'équipement
+		if inv.selected%gridCols == gridCols-1 || inv.selected >= len(vis)-1 {
+			inv.equipFocus = true
+		} else {
+			inv.selected++
+		}
+	}
+	if IsKeyJustPressed(ebiten.KeyLeft) && inv.selected%gridCols > 0 {
+		inv.mouseMode = false
 		inv.selected--
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) && inv.selected < len(inv.Items)-1 {
-		inv.selected++
+	if IsKeyJustPressed(ebiten.KeyUp) && inv.selected >= gridCols {
+		inv.mouseMode = false
+		inv.selected -= gridCols
+	}
+	if IsKeyJustPressed(ebiten.KeyDown) && inv.selected+gridCols < len(vis) {
+		inv.mouseMode = false
+		inv.selected += gridCols
 	}
 
-	// Consommer l'objet sélectionné
-	if ebiten.IsKeyPressed(ebiten.KeyEnter) && len(inv.Items) > 0 {
-		selectedItem := inv.Items[inv.selected]
+	if IsKeyJustPressed(ebiten.KeyEnter) && inv.selected < len(vis) {
+		inv.mouseMode = false
+		inv.activate(player, vis[inv.selected])
+	}
+}
 
-		if selectedItem == "Cristalline - mystérieuse" ||
-			selectedItem == "Cristalline - tonic" ||
-			selectedItem == "Cristalline - suspicieuse" {
+// updateEquipment gère le panneau d'équipement au clavier : Entrée retire l'objet de l'emplacement sélectionné
+func (inv *Inventaire) updateEquipment(player *Player) {
+	if IsKeyJustPressed(ebiten.KeyLeft) {
+		inv.mouseMode = false
+		inv.equipFocus = false
+		return
+	}
+	if IsKeyJustPressed(ebiten.KeyUp) {
+		inv.mouseMode = false
+		inv.slotSelected = (inv.slotSelected + len(equipSlots) - 1) % len(equipSlots)
+	}
+	if IsKeyJustPressed(ebiten.KeyDown) {
+		inv.mouseMode = false
+		inv.slotSelected = (inv.slotSelected + 1) % len(equipSlots)
+	}
+	if IsKeyJustPressed(ebiten.KeyEnter) {
+		inv.mouseMode = false
+		inv.unequip(player, equipSlots[inv.slotSelected])
+	}
+}
 
-			// ✅ Cristallines normales → +50 ego
-			player.BonusEgo = 50
-			AddNotification("Tu as bu une Cristalline, ton ego est boosté !")
+// updateMouse gère la souris : filtres, tris, molette, glisser-déposer et clic droit
+func (inv *Inventaire) updateMouse(player *Player) {
+	p := image.Pt(ebiten.CursorPosition())
+	if p != inv.lastMouse {
+		inv.mouseMode = true
+	}
+	inv.lastMouse = p
+
+	// Molette : une ligne par cran
+	if _, dy := ebiten.Wheel(); dy > 0 {
+		inv.scroll--
+		inv.mouseMode = true
+	} else if dy < 0 {
+		inv.scroll++
+		inv.mouseMode = true
+	}
 
-			// Retirer après usage
-			inv.Items = append(inv.Items[:inv.selected], inv.Items[inv.selected+1:]...)
+	vis := inv.visible()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		inv.mouseMode = true
+		for i := range inventoryFilters {
+			if p.In(filterRect(i)) {
+				inv.filter, inv.selected, inv.scroll = i, 0, 0
+				return
+			}
+		}
+		for i, s := range inventorySorts {
+			if p.In(sortRect(i)) {
+				inv.sortBy(s.Less)
+				return
+			}
+		}
+		if pos := inv.cellAt(p); pos >= 0 && pos < len(vis) {
+			inv.selected, inv.equipFocus = pos, false
+			inv.drag = itemDrag{active: true, from: vis[pos], id: inv.Items[vis[pos]].ID}
+		} else if s := slotAt(p); s >= 0 && player != nil {
+			inv.slotSelected, inv.equipFocus = s, true
+			if it, ok := player.Equipment[equipSlots[s]]; ok {
+				inv.drag = itemDrag{active: true, from: -1, slot: equipSlots[s], id: it.ID}
+			}
+		}
+	}
 
-		} else if selectedItem == "Cristalline - big" {
-			// ✅ BIG Cristalline → +100 ego
-			player.BonusEgo = 100
-			AddNotification("Tu as bu une BIG Cristalline ! Ton ego sera boosté de +100 au prochain combat.")
+	if inv.drag.active && inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
+		inv.drop(player, p)
+		inv.drag = itemDrag{}
+		return
+	}
 
-			// Retirer après usage
-			inv.Items = append(inv.Items[:inv.selected], inv.Items[inv.selected+1:]...)
+	// Clic droit : utiliser ou équiper un objet du sac, retirer un objet porté
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && !inv.drag.active {
+		if pos := inv.cellAt(p); pos >= 0 && pos < len(vis) {
+			inv.selected, inv.equipFocus = pos, false
+			inv.activate(player, vis[pos])
+		} else if s := slotAt(p); s >= 0 {
+			inv.slotSelected, inv.equipFocus = s, true
+			inv.unequip(player, equipSlots[s])
+		}
+	}
+}
 
-		} else if selectedItem == "Micro" {
-			// ✅ Micro → +10 ego
-			player.BonusEgo = 10
-			AddNotification("Tu as utilisé le Micro, ton ego sera boosté de +10 au prochain combat !")
+// drop pose l'objet tiré à la souris sous le curseur
+func (inv *Inventaire) drop(player *Player, p image.Point) {
+	d := inv.drag
+	if s := slotAt(p); s >= 0 {
+		if d.from < 0 || player == nil {
+			return // Objet déjà porté : il reste à sa place
+		}
+		if def, _ := LookupItem(d.id); def.Slot != equipSlots[s] {
+			AddNotification(fmt.Sprintf("%s ne va pas dans l'emplacement %s", ItemName(d.id), slotNames[equipSlots[s]]))
+			return
+		}
+		inv.equip(player, d.from)
+		return
+	}
 
-			inv.Items = append(inv.Items[:inv.selected], inv.Items[inv.selected+1:]...)
+	pos := inv.cellAt(p)
+	if pos < 0 {
+		return // Lâché hors de la grille : rien ne bouge
+	}
+	if d.from < 0 {
+		inv.unequip(player, d.slot)
+		return
+	}
+	if vis := inv.visible(); pos < len(vis) {
+		inv.moveStack(d.from, vis[pos])
+	} else {
+		inv.moveToEnd(d.from)
+	}
+	inv.selected = pos
+}
 
-		} else if selectedItem == "Cigarette électronique" {
-			// ✅ Cigarette → -15 ego ennemi
-			player.PendingEnemyEgoDebuff = 15
-			AddNotification("Tu as utilisé la Cigarette électronique, l'ennemi commencera avec -15 ego !")
+// activate utilise ou équipe la pile i (Entrée ou clic droit)
+func (inv *Inventaire) activate(player *Player, i int) {
+	if player == nil {
+		return
+	}
+	id := inv.Items[i].ID
+	if d, ok := LookupItem(id); ok && d.Slot != "" {
+		inv.equip(player, i)
+		return
+	}
+	msg, used := UseItem(player, id)
+	AddNotification(msg)
+	if used {
+		inv.removeAt(i)
+	}
+}
 
-			inv.Items = append(inv.Items[:inv.selected], inv.Items[inv.selected+1:]...)
+// equip porte un exemplaire de la pile i ; l'objet qu'il remplace revient dans le sac
+func (inv *Inventaire) equip(player *Player, i int) {
+	it := inv.Items[i].Item
+	previous, ok := player.Equip(it)
+	if !ok {
+		AddNotification("Pas assez d'ego pour cet échange : repose-toi d'abord")
+		return
+	}
+	inv.removeAt(i)
+	if previous.ID != "" {
+		inv.Add(previous)
+	}
+	AddNotification(fmt.Sprintf("%s équipé (%s)", it.Label(), it.Stats()))
+}
 
-		}
+// unequip retire l'objet d'un emplacement et le range dans le sac
+func (inv *Inventaire) unequip(player *Player, slot string) {
+	if player == nil {
+		return
+	}
+	it, ok := player.Unequip(slot)
+	switch {
+	case !ok:
+		AddNotification("Pas assez d'ego pour retirer cet objet : repose-toi d'abord")
+	case it.ID != "":
+		inv.Add(it)
+		AddNotification(it.Label() + " retiré")
+	default:
+		AddNotification("Rien à retirer : emplacement " + slotNames[slot] + " vide")
+	}
+}
 
-		// ✅ Ajuster la sélection après suppression
-		if inv.selected >= len(inv.Items) {
-			inv.selected = len(inv.Items) - 1
+// moveStack pose la pile from sur la pile to : elles fusionnent si c'est le même objet commun, sinon elles s'échangent
+func (inv *Inventaire) moveStack(from, to int) {
+	if from == to {
+		return
+	}
+	a, b := &inv.Items[from], &inv.Items[to]
+	if a.ID == b.ID && !a.Unique() && !b.Unique() {
+		if moved := min(ItemStackSize(a.ID)-b.Count, a.Count); moved > 0 {
+			b.Count += moved
+			a.Count -= moved
+			if a.Count == 0 {
+				inv.Items = append(inv.Items[:from], inv.Items[from+1:]...)
+			}
+			return
 		}
 	}
+	inv.Items[from], inv.Items[to] = inv.Items[to], inv.Items[from]
 }
 
+// moveToEnd déplace la pile from à la fin de la grille
+func (inv *Inventaire) moveToEnd(from int) {
+	st := inv.Items[from]
+	inv.Items = append(inv.Items[:from], inv.Items[from+1:]...)
+	inv.Items = append(inv.Items, st)
+}
+
+// sortBy trie les piles puis regroupe les piles entamées d'un même objet
+func (inv *Inventaire) sortBy(less func(a, b ItemDef) bool) {
+	sort.SliceStable(inv.Items, func(i, j int) bool {
+		a, _ := LookupItem(inv.Items[i].ID)
+		b, _ := LookupItem(inv.Items[j].ID)
+		return less(a, b)
+	})
+	inv.Items = inv.Items.Compact()
+}
+
+// -----------------
+// Draw
+// -----------------
 func (inv *Inventaire) Draw(screen *ebiten.Image, g *Game) {
 	if !inv.Open {
 		return
@@ -183,154 +557,310 @@ func (inv *Inventaire) Draw(screen *ebiten.Image, g *Game) {
 		screen.Fill(color.RGBA{0, 0, 0, 200})
 	}
 
-	// Paramètres
-	lineHeight := 100 // espacement vertical entre chaque item
-	iconScale := 0.08 // taille des icônes
-	iconSpacing := 20 // espace entre texte et icône
-
 	// Police : on utilise PressStart2P
 	face := g.fontSmall
 	if face == nil {
 		face = basicfont.Face7x13 // fallback si jamais la police ne charge pas
 	}
+	yellow := color.RGBA{255, 255, 0, 255}
+	grey := color.RGBA{180, 180, 180, 255}
 
-	// Calculer largeur max du texte
-	maxTextWidth := 0
-	for _, item := range inv.Items {
-		w := text.BoundString(face, item).Dx()
-		if w > maxTextWidth {
-			maxTextWidth = w
-		}
+	text.Draw(screen, "Fausse sacoche Gucci", face, gridX, gridY-140, yellow)
+
+	// Filtres et tris
+	for i, f := range inventoryFilters {
+		drawInventoryButton(screen, filterRect(i), f.Label, face, i == inv.filter)
+	}
+	for i, s := range inventorySorts {
+		drawInventoryButton(screen, sortRect(i), s.Label, face, false)
 	}
 
-	// Largeur max des icônes
-	maxIconWidth := 0
-	for _, icon := range inv.Icons {
-		if icon == nil {
-			continue
-		}
-		w, _ := icon.Size()
-		if w > maxIconWidth {
-			maxIconWidth = w
+	// Cases de la grille
+	vis := inv.visible()
+	for row := 0; row < gridRows; row++ {
+		for col := 0; col < gridCols; col++ {
+			pos := (row+inv.scroll)*gridCols + col
+			r := cellRect(pos, inv.scroll)
+			selected := pos == inv.selected && !inv.equipFocus && len(vis) > 0
+			drawInventoryCell(screen, r, selected, false)
+			if pos >= len(vis) {
+				continue
+			}
+			st := inv.Items[vis[pos]]
+			count := st.Count
+			if inv.drag.active && inv.drag.from == vis[pos] {
+				count-- // L'exemplaire tiré suit le curseur
+			}
+			if count > 0 {
+				drawItemIcon(screen, st.ID, r, face)
+				drawRarityFrame(screen, r, st.Rarity)
+			}
+			if count > 1 {
+				label := fmt.Sprintf("x%d", count)
+				text.Draw(screen, label, face, r.Max.X-text.BoundString(face, label).Dx()-6, r.Max.Y-6, color.RGBA{255, 220, 120, 255})
+			}
 		}
 	}
+	if len(vis) == 0 {
+		text.Draw(screen, "Rien ici", face, gridX+20, gridY+60, grey)
+	}
 
-	totalWidth := maxTextWidth + iconSpacing + int(float64(maxIconWidth)*iconScale)
-
-	// Centrage
-	screenWidth, screenHeight := screen.Size()
-	startX := (screenWidth - totalWidth) / 2
-	startY := (screenHeight - lineHeight*len(inv.Items)) / 2
+	// Barre de défilement
+	if total := maxScroll(len(vis)); total > 0 {
+		x := float32(gridX + gridCols*gridCell)
+		h := float32(gridRows * gridCell)
+		vector.DrawFilledRect(screen, x, gridY, 8, h, color.RGBA{60, 60, 60, 200}, false)
+		thumb := h * float32(gridRows) / float32(gridRows+total)
+		y := float32(gridY) + (h-thumb)*float32(inv.scroll)/float32(total)
+		vector.DrawFilledRect(screen, x, y, 8, thumb, grey, false)
+	}
 
-	iconColumnX := startX + maxTextWidth + iconSpacing
+	inv.drawEquipment(screen, g.player, face)
 
-	for i, item := range inv.Items {
-		textX := startX
-		textY := startY + i*lineHeight
+	// Aide
+	helpY := gridY + gridRows*gridCell + 40
+	text.Draw(screen, "Glisser-déposer : ranger, équiper, retirer", face, gridX, helpY, grey)
+	text.Draw(screen, "Clic droit / Entrée : utiliser ou équiper", face, gridX, helpY+30, grey)
+	text.Draw(screen, "Molette : défiler   Flèches : se déplacer", face, gridX, helpY+60, grey)
 
-		// --- Texte ---
-		text.Draw(screen, item, face, textX, textY, color.White)
+	// Objet tiré, sous le curseur
+	if inv.drag.active {
+		p := inv.lastMouse
+		drawItemIcon(screen, inv.drag.id, image.Rect(p.X-gridCellSize/2, p.Y-gridCellSize/2, p.X+gridCellSize/2, p.Y+gridCellSize/2), face)
+		return
+	}
+	inv.drawTooltip(screen, g.player, face)
+}
 
-		// --- Flèche de sélection jaune ▶ ---
-		if i == inv.selected {
-			arrow := "▶"
-			arrowX := textX - 40 // un peu plus proche
-			yellow := color.RGBA{255, 255, 0, 255}
-			text.Draw(screen, arrow, face, arrowX, textY, yellow)
+// drawEquipment dessine le panneau d'équipement, à droite de la grille
+func (inv *Inventaire) drawEquipment(screen *ebiten.Image, player *Player, face font.Face) {
+	if player == nil {
+		return
+	}
+	yellow := color.RGBA{255, 255, 0, 255}
+	grey := color.RGBA{180, 180, 180, 255}
+	green := color.RGBA{120, 220, 120, 255}
+
+	// Emplacement où l'objet tiré peut aller
+	target := ""
+	if inv.drag.active && inv.drag.from >= 0 {
+		if d, ok := LookupItem(inv.drag.id); ok {
+			target = d.Slot
 		}
+	}
 
-		// --- Icône alignée avec le texte ---
-		if icon, ok := inv.Icons[item]; ok && icon != nil {
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Scale(iconScale, iconScale)
+	text.Draw(screen, "Équipement", face, equipX, equipY-30, yellow)
+	for i, slot := range equipSlots {
+		r := slotRect(i)
+		drawInventoryCell(screen, r, inv.equipFocus && i == inv.slotSelected, slot == target)
 
-			// On centre l’icône sur la ligne de texte
-			_, ih := icon.Size()
-			iconY := float64(textY) - float64(ih)*iconScale/2 + float64(face.Metrics().Ascent.Round())/2
+		it := player.Equipment[slot]
+		if it.ID != "" && !(inv.drag.active && inv.drag.from < 0 && inv.drag.slot == slot) {
+			drawItemIcon(screen, it.ID, r, face)
+			drawRarityFrame(screen, r, it.Rarity)
+		}
+		x := r.Max.X + 20
+		text.Draw(screen, slotNames[slot], face, x, r.Min.Y+30, grey)
+		if it.ID == "" {
+			text.Draw(screen, "-", face, x, r.Min.Y+60, color.White)
+			continue
+		}
+		text.Draw(screen, it.Name(), face, x, r.Min.Y+60, it.Rarity.Color())
+		text.Draw(screen, it.Stats().String(), face, x, r.Min.Y+90, green)
+	}
 
-			op.GeoM.Translate(float64(iconColumnX), iconY)
-			screen.DrawImage(icon, op)
+	// Stats totales, équipement compris
+	stats := player.Stats()
+	y := equipY + len(equipSlots)*equipStep + 20
+	text.Draw(screen, fmt.Sprintf("Ego max %d", stats.Ego), face, equipX, y, color.White)
+	text.Draw(screen, fmt.Sprintf("Flow %d  Charisme %d", stats.Flow, stats.Charisma), face, equipX, y+30, color.White)
+}
+
+// drawTooltip dessine la description de l'objet survolé (ou sélectionné au clavier)
+func (inv *Inventaire) drawTooltip(screen *ebiten.Image, player *Player, face font.Face) {
+	var it Item
+	var anchor image.Point
+	vis := inv.visible()
+	switch {
+	case inv.mouseMode:
+		p := inv.lastMouse
+		if pos := inv.cellAt(p); pos >= 0 && pos < len(vis) {
+			it = inv.Items[vis[pos]].Item
+		} else if s := slotAt(p); s >= 0 && player != nil {
+			it = player.Equipment[equipSlots[s]]
 		}
+		anchor = p.Add(image.Pt(20, 20))
+	case !inv.equipFocus && inv.selected < len(vis):
+		it = inv.Items[vis[inv.selected]].Item
+		r := cellRect(inv.selected, inv.scroll)
+		anchor = image.Pt(r.Max.X+10, r.Min.Y)
+	}
+	d, ok := LookupItem(it.ID)
+	if !ok {
+		return
+	}
+
+	type line struct {
+		text string
+		clr  color.Color
+	}
+	lines := []line{{it.Label(), it.Rarity.Color()}, {categoryNames[d.Category], color.RGBA{180, 180, 180, 255}}}
+	for _, l := range wrapText(d.Description, 32) {
+		lines = append(lines, line{l, color.White})
+	}
+	if details := d.Details(); details != "" {
+		lines = append(lines, line{details, color.RGBA{120, 220, 120, 255}})
+	}
+	if affixes := it.AffixText(); affixes != "" {
+		lines = append(lines, line{"Propriétés : " + affixes, it.Rarity.Color()})
+	}
+
+	const pad, lineH = 12, 26
+	w := 0
+	for _, l := range lines {
+		w = max(w, text.BoundString(face, l.text).Dx())
+	}
+	w += 2 * pad
+	h := len(lines)*lineH + 2*pad
+	// Garde l'infobulle dans l'écran
+	screenW, screenH := screen.Size()
+	x := min(anchor.X, screenW-w-10)
+	y := min(anchor.Y, screenH-h-10)
+
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h), color.RGBA{15, 15, 25, 235}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 2, color.RGBA{255, 255, 0, 255}, false)
+	for i, l := range lines {
+		text.Draw(screen, l.text, face, x+pad, y+pad+(i+1)*lineH-8, l.clr)
 	}
 }
 
+// drawInventoryCell dessine une case vide ; bordure jaune si sélectionnée, verte si l'objet tiré peut y aller
+func drawInventoryCell(screen *ebiten.Image, r image.Rectangle, selected, target bool) {
+	x, y, w, h := float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy())
+	vector.DrawFilledRect(screen, x, y, w, h, color.RGBA{20, 20, 20, 200}, false)
+	border, width := color.Color(color.RGBA{90, 90, 90, 255}), float32(2)
+	switch {
+	case selected:
+		border, width = color.RGBA{255, 255, 0, 255}, 4
+	case target:
+		border, width = color.RGBA{120, 220, 120, 255}, 4
+	}
+	vector.StrokeRect(screen, x, y, w, h, width, border, false)
+}
+
+// drawRarityFrame entoure une case de la couleur de la rareté (rien pour un objet commun)
+func drawRarityFrame(screen *ebiten.Image, r image.Rectangle, rarity Rarity) {
+	if rarity == RarityCommon {
+		return
+	}
+	in := r.Inset(6)
+	vector.StrokeRect(screen, float32(in.Min.X), float32(in.Min.Y), float32(in.Dx()), float32(in.Dy()), 2, rarity.Color(), false)
+}
+
+// drawItemIcon dessine l'icône d'un objet centrée dans une case (ses initiales s'il n'a pas d'icône)
+func drawItemIcon(screen *ebiten.Image, id string, r image.Rectangle, face font.Face) {
+	center := r.Min.Add(r.Max).Div(2)
+	icon := ItemIcon(id)
+	if icon == nil {
+		label := []rune(ItemName(id))
+		label = label[:min(len(label), 3)]
+		w := text.BoundString(face, string(label)).Dx()
+		text.Draw(screen, string(label), face, center.X-w/2, center.Y+face.Metrics().Ascent.Round()/2, color.White)
+		return
+	}
+	iw, ih := icon.Size()
+	scale := float64(gridIconSize) / float64(max(iw, ih))
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(float64(center.X)-float64(iw)*scale/2, float64(center.Y)-float64(ih)*scale/2)
+	screen.DrawImage(icon, op)
+}
+
+// drawInventoryButton dessine un bouton de filtre ou de tri (fond doré si actif)
+func drawInventoryButton(screen *ebiten.Image, r image.Rectangle, label string, face font.Face, active bool) {
+	bg := color.RGBA{40, 40, 40, 220}
+	if active {
+		bg = color.RGBA{120, 90, 20, 230}
+	}
+	vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), bg, false)
+	vector.StrokeRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), 2, color.RGBA{180, 180, 180, 255}, false)
+	w := text.BoundString(face, label).Dx()
+	text.Draw(screen, label, face, r.Min.X+(r.Dx()-w)/2, r.Min.Y+r.Dy()/2+face.Metrics().Ascent.Round()/2, color.White)
+}
+
 // -----------------
 // DrawNote
 // -----------------
 func (inv *Inventaire) DrawNote(screen *ebiten.Image) {
 	ebitenutil.DebugPrintAt(screen, "Appuie sur [TAB] pour ouvrir la FAUSSE sacoche Gucci", 20, 20)
-	ebitenutil.DebugPrintAt(screen, "Appuie sur [F] pour ouvrir craft", 20, 40)
+	ebitenutil.DebugPrintAt(screen, "Appuie sur [F] pour ouvrir craft, [R] pour revoir tes combats", 20, 40)
 }
 
 // -----------------
 // Fonctions utilitaires pour l'inventaire
 // -----------------
 
-// Vérifie si l'inventaire contient au moins une Cristalline (peu importe laquelle)
-func (inv *Inventaire) HasCristalline() bool {
-	for _, item := range inv.Items {
-		if item == "Cristalline - mystérieuse" ||
-			item == "Cristalline - tonic" ||
-			item == "Cristalline - suspicieuse" ||
-			item == "Cristalline - big" {
+// Vérifie si l'inventaire contient un objet d'une famille (tag du registre)
+func (inv *Inventaire) HasTag(tag string) bool {
+	for _, st := range inv.Items {
+		if d, ok := LookupItem(st.ID); ok && d.HasTag(tag) {
 			return true
 		}
 	}
 	return false
 }
 
-// Vérifie si l'inventaire contient un objet précis
+// Vérifie si l'inventaire contient un objet précis (identifiant ou nom)
 func (inv *Inventaire) HasItem(name string) bool {
-	for _, item := range inv.Items {
-		if item == name {
+	id := ItemID(name)
+	for _, st := range inv.Items {
+		if st.ID == id {
 			return true
 		}
 	}
 	return false
 }
 
-// Retire la première Cristalline simple trouvée (mystérieuse / tonic / suspicieuse)
-// Retourne true si une cristalline a été supprimée, false sinon.
-func (inv *Inventaire) RemoveCristalline() bool {
-	for i, item := range inv.Items {
-		if item == "Cristalline - mystérieuse" ||
-			item == "Cristalline - tonic" ||
-			item == "Cristalline - suspicieuse" {
-			// supprimer l'icône si présente
-			if inv.Icons != nil {
-				delete(inv.Icons, item)
-			}
-			// retirer de la slice
-			inv.Items = append(inv.Items[:i], inv.Items[i+1:]...)
-			// ajuster sélection
-			if inv.selected >= len(inv.Items) {
-				inv.selected = len(inv.Items) - 1
-				if inv.selected < 0 {
-					inv.selected = 0
-				}
-			}
-			return true
+// Count retourne le nombre d'exemplaires d'un objet précis (identifiant ou nom) dans le sac
+func (inv *Inventaire) Count(name string) int {
+	id := ItemID(name)
+	n := 0
+	for _, st := range inv.Items {
+		if st.ID == id {
+			n += st.Count
 		}
 	}
-	return false
+	return n
+}
+
+// Retire un objet d'une famille. Retourne son identifiant, et false si aucun n'a été trouvé.
+func (inv *Inventaire) RemoveTag(tag string) (string, bool) {
+	for i, st := range inv.Items {
+		if d, ok := LookupItem(st.ID); ok && d.HasTag(tag) {
+			inv.removeAt(i)
+			return st.ID, true
+		}
+	}
+	return "", false
 }
 
-// Retire un objet précis (par nom). Retourne true si supprimé, false sinon.
+// Retire un exemplaire d'un objet précis (identifiant ou nom). Retourne true si supprimé, false sinon.
 func (inv *Inventaire) RemoveItem(name string) bool {
-	for i, item := range inv.Items {
-		if item == name {
-			if inv.Icons != nil {
-				delete(inv.Icons, item)
-			}
-			inv.Items = append(inv.Items[:i], inv.Items[i+1:]...)
-			if inv.selected >= len(inv.Items) {
-				inv.selected = len(inv.Items) - 1
-				if inv.selected < 0 {
-					inv.selected = 0
-				}
-			}
+	id := ItemID(name)
+	for i, st := range inv.Items {
+		if st.ID == id {
+			inv.removeAt(i)
 			return true
 		}
 	}
 	return false
 }
+
+// removeAt retire un exemplaire de la pile i (la pile disparaît quand elle est vide)
+func (inv *Inventaire) removeAt(i int) {
+	inv.Items[i].Count--
+	if inv.Items[i].Count > 0 {
+		return
+	}
+	inv.Items = append(inv.Items[:i], inv.Items[i+1:]...)
+}
@@ -0,0 +1,223 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"encoding/json" // Les objets sont décrits en JSON
+	"fmt"           // Messages d'utilisation
+	"log"           // Erreurs de chargement
+	"os"            // Lecture du fichier
+	"sync"          // Chargement unique du registre
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// -----------------
+// Registre des objets
+// -----------------
+
+// Fichier des définitions d'objets
+const itemsPath = "assets/items.json"
+
+// Catégories d'objets
+const (
+	CategoryConsumable = "consumable" // S'utilise depuis l'inventaire
+	CategoryMaterial   = "material"   // Sert au forgeron
+	CategoryEquipment  = "equipment"  // Se porte (voir equipment.go)
+)
+
+// Noms affichés des catégories
+var categoryNames = map[string]string{
+	CategoryConsumable: "Consommable",
+	CategoryMaterial:   "Matériau",
+	CategoryEquipment:  "Équipement",
+}
+
+// Effets des objets consommables
+const (
+	EffectBonusEgo    = "bonus_ego"    // Ego en plus au prochain combat
+	EffectEnemyDebuff = "enemy_debuff" // L'ennemi commence le prochain combat avec moins d'ego
+	EffectHeal        = "heal"         // Rend de l'ego tout de suite
+)
+
+// ItemEffect est l'effet d'un objet quand on l'utilise
+type ItemEffect struct {
+	Kind   string `json:"kind"`   // Voir Effect...
+	Amount int    `json:"amount"` // Valeur de l'effet
+}
+
+// ItemDef décrit un objet
+type ItemDef struct {
+	ID          string      `json:"id"`                // Identifiant stable (enregistré dans les saves)
+	Name        string      `json:"name"`              // Nom affiché
+	Description string      `json:"description"`       // Description (infobulle de l'inventaire)
+	Price       int         `json:"price"`             // Valeur de base (le marchand en reprend une partie)
+	Icon        string      `json:"icon"`              // Chemin de l'icône
+	Category    string      `json:"category"`          // Voir Category...
+	StackSize   int         `json:"stack_size"`        // Nombre maximum par pile
+	Class       string      `json:"class,omitempty"`   // Version réservée à une classe
+	Slot        string      `json:"slot,omitempty"`    // Emplacement d'équipement (Slot...), vide si l'objet ne se porte pas
+	Stats       Stats       `json:"stats"`             // Bonus tant que l'objet est porté
+	Tags        []string    `json:"tags,omitempty"`    // Familles ("cristalline", "upgradable"...)
+	Aliases     []string    `json:"aliases,omitempty"` // Anciens noms encore présents dans les saves
+	Effect      *ItemEffect `json:"effect,omitempty"`  // Effet à l'utilisation (nil : ne s'utilise pas)
+}
+
+// HasTag indique si l'objet appartient à une famille
+func (d ItemDef) HasTag(tag string) bool {
+	return contains(d.Tags, tag)
+}
+
+// Details décrit l'effet ou les bonus d'un objet ("" s'il n'en a pas)
+func (d ItemDef) Details() string {
+	if d.Slot != "" {
+		return fmt.Sprintf("Se porte (%s) : %s", slotNames[d.Slot], d.Stats)
+	}
+	if d.Effect == nil {
+		return ""
+	}
+	switch d.Effect.Kind {
+	case EffectBonusEgo:
+		return fmt.Sprintf("+%d Ego au prochain combat", d.Effect.Amount)
+	case EffectEnemyDebuff:
+		return fmt.Sprintf("L'ennemi perd %d Ego au prochain combat", d.Effect.Amount)
+	case EffectHeal:
+		return fmt.Sprintf("Rend %d Ego", d.Effect.Amount)
+	}
+	return ""
+}
+
+// ItemRegistry contient toutes les définitions d'objets
+type ItemRegistry struct {
+	defs  []ItemDef
+	byKey map[string]int           // Index par identifiant, nom et ancien nom
+	icons map[string]*ebiten.Image // Icônes déjà chargées, par identifiant
+}
+
+// LoadItemRegistry charge les définitions d'objets
+func LoadItemRegistry(path string) (*ItemRegistry, error) {
+	r := &ItemRegistry{byKey: map[string]int{}, icons: map[string]*ebiten.Image{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r.defs); err != nil {
+		return r, err
+	}
+	for i, d := range r.defs {
+		for _, key := range append([]string{d.ID, d.Name}, d.Aliases...) {
+			r.byKey[key] = i
+		}
+	}
+	return r, nil
+}
+
+var (
+	itemsOnce    sync.Once
+	itemRegistry *ItemRegistry
+)
+
+// loadedItems retourne le registre, chargé au premier appel
+func loadedItems() *ItemRegistry {
+	itemsOnce.Do(func() {
+		var err error
+		itemRegistry, err = LoadItemRegistry(itemsPath)
+		if err != nil {
+			log.Println("Impossible de charger les objets :", err)
+		}
+	})
+	return itemRegistry
+}
+
+// LookupItem retrouve un objet par identifiant, nom ou ancien nom
+func LookupItem(key string) (ItemDef, bool) {
+	i, ok := loadedItems().byKey[key]
+	if !ok {
+		return ItemDef{}, false
+	}
+	return loadedItems().defs[i], true
+}
+
+// ItemID retourne l'identifiant d'un objet (la clé telle quelle pour un objet inconnu)
+func ItemID(key string) string {
+	if d, ok := LookupItem(key); ok {
+		return d.ID
+	}
+	return key
+}
+
+// ItemName retourne le nom affiché d'un objet
+func ItemName(key string) string {
+	if d, ok := LookupItem(key); ok {
+		return d.Name
+	}
+	return key
+}
+
+// ItemIcon retourne l'icône d'un objet (nil si inconnu ou sans icône)
+func ItemIcon(key string) *ebiten.Image {
+	d, ok := LookupItem(key)
+	if !ok || d.Icon == "" {
+		return nil
+	}
+	r := loadedItems()
+	if img, loaded := r.icons[d.ID]; loaded {
+		return img
+	}
+	img := LoadImage(d.Icon)
+	r.icons[d.ID] = img
+	return img
+}
+
+// ItemStackSize retourne le nombre maximum d'exemplaires par pile (1 pour un objet inconnu)
+func ItemStackSize(key string) int {
+	d, _ := LookupItem(key)
+	return max(d.StackSize, 1)
+}
+
+// ItemForClass retourne l'objet d'une famille réservé à une classe
+func ItemForClass(tag, class string) (ItemDef, bool) {
+	for _, d := range loadedItems().defs {
+		if d.Class != "" && d.Class == class && d.HasTag(tag) {
+			return d, true
+		}
+	}
+	return ItemDef{}, false
+}
+
+// UseItem applique l'effet d'un objet au joueur. Retourne le message à afficher
+// et false si l'objet ne s'utilise pas (il reste alors dans l'inventaire).
+func UseItem(player *Player, key string) (string, bool) {
+	d, ok := LookupItem(key)
+	if !ok || d.Effect == nil {
+		return ItemName(key) + " ne s'utilise pas", false
+	}
+	switch d.Effect.Kind {
+	case EffectBonusEgo:
+		player.BonusEgo += d.Effect.Amount
+		return fmt.Sprintf("%s : +%d Ego au prochain combat", d.Name, d.Effect.Amount), true
+	case EffectEnemyDebuff:
+		player.PendingEnemyEgoDebuff += d.Effect.Amount
+		return fmt.Sprintf("%s : l'ennemi perd %d Ego au prochain combat", d.Name, d.Effect.Amount), true
+	case EffectHeal:
+		healed := player.Heal(d.Effect.Amount)
+		return fmt.Sprintf("%s : +%d Ego", d.Name, healed), true
+	}
+	log.Println("Effet d'objet inconnu :", d.Effect.Kind)
+	return ItemName(key) + " ne s'utilise pas", false
+}
+
+// ItemBattleBonuses retourne le meilleur bonus d'ego et le meilleur malus ennemi que donnent des objets
+func ItemBattleBonuses(keys []string) (bonus, debuff int) {
+	for _, key := range keys {
+		d, ok := LookupItem(key)
+		if !ok || d.Effect == nil {
+			continue
+		}
+		switch d.Effect.Kind {
+		case EffectBonusEgo:
+			bonus = max(bonus, d.Effect.Amount)
+		case EffectEnemyDebuff:
+			debuff = max(debuff, d.Effect.Amount)
+		}
+	}
+	return bonus, debuff
+}
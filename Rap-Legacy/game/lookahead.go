@@ -0,0 +1,113 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	mrand "math/rand/v2" // Générateur des simulations
+	"time"               // Pour le budget de réflexion
+)
+
+// -----------------
+// IA qui anticipe (boss et mode difficile)
+// -----------------
+
+// LookaheadConfig règle le budget de l'IA qui anticipe
+type LookaheadConfig struct {
+	Rollouts int           // Parties simulées par action possible
+	Depth    int           // Actions jouées au maximum dans chaque partie simulée
+	Budget   time.Duration // Temps de réflexion maximum par choix (0 = pas de limite, résultat reproductible)
+}
+
+// Réglage par défaut : tient largement dans une frame (16ms)
+var DefaultLookahead = LookaheadConfig{Rollouts: 32, Depth: 12, Budget: 4 * time.Millisecond}
+
+// LookaheadController évalue chaque action possible par des parties simulées au hasard
+// (Monte Carlo) sur une copie du moteur, et joue celle qui gagne le plus souvent.
+// Les copies ont leur propre hasard et la garde en cours de l'adversaire y est tirée au sort :
+// l'IA ne triche ni sur les coups critiques ni sur la défense choisie.
+type LookaheadController struct {
+	cfg LookaheadConfig
+	rng *mrand.Rand
+}
+
+// NewLookaheadController crée une IA qui anticipe, reproductible à partir de la seed du combat
+func NewLookaheadController(seed uint64, cfg LookaheadConfig) *LookaheadController {
+	if cfg.Rollouts <= 0 {
+		cfg.Rollouts = 1
+	}
+	return &LookaheadController{cfg: cfg, rng: mrand.New(mrand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+func (l *LookaheadController) ChooseMove(b *Battle, side Side) (int, bool) {
+	return l.Pick(b.combat, side), true
+}
+
+// Pick simule chaque action possible et retourne la meilleure
+func (l *LookaheadController) Pick(c *Combat, side Side) int {
+	var moves []int
+	for _, m := range c.Moves(side) {
+		if c.CanUse(side, m) {
+			moves = append(moves, m)
+		}
+	}
+	if len(moves) == 0 {
+		return MoveBreathe
+	}
+
+	// Une partie simulée par action à chaque passe, jusqu'au nombre de passes ou à la fin du budget
+	start := time.Now()
+	scores := make([]float64, len(moves))
+	for pass := 0; pass < l.cfg.Rollouts; pass++ {
+		for i, m := range moves {
+			scores[i] += l.rollout(c, side, m)
+		}
+		if l.cfg.Budget > 0 && time.Since(start) >= l.cfg.Budget {
+			break
+		}
+	}
+
+	best := 0
+	for i := range moves {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	return moves[best]
+}
+
+// rollout joue move puis termine la partie au hasard, et retourne le résultat pour side (0 à 1)
+func (l *LookaheadController) rollout(c *Combat, side Side, move int) float64 {
+	sim := c.Clone(l.rng.Uint64())
+	if opp := side.Other(); sim.guard[opp] != noGuard {
+		sim.guard[opp] = defenseMoves[l.rng.IntN(len(defenseMoves))] // Garde cachée : on devine
+	}
+
+	next, ok := sim.Step(side, move)
+	for depth := 1; ok && depth < l.cfg.Depth; depth++ {
+		next, ok = sim.Step(next, randomMove(sim, next, l.rng))
+	}
+	return evaluate(sim, side)
+}
+
+// randomMove tire une action possible au hasard (politique des parties simulées)
+func randomMove(c *Combat, s Side, r *mrand.Rand) int {
+	moves := c.Moves(s)
+	for tries := 0; tries < 8; tries++ {
+		if m := moves[r.IntN(len(moves))]; c.CanUse(s, m) {
+			return m
+		}
+	}
+	return MoveBreathe // Toujours possible
+}
+
+// evaluate note une position pour side : 1 gagné, 0 perdu, sinon selon l'écart d'ego
+func evaluate(c *Combat, side Side) float64 {
+	mine, theirs := c.Fighters[side].Ego, c.Fighters[side.Other()].Ego
+	switch {
+	case mine <= 0 && theirs <= 0:
+		return 0.5
+	case theirs <= 0:
+		return 1
+	case mine <= 0:
+		return 0
+	}
+	return 0.5 + 0.4*float64(mine-theirs)/float64(mine+theirs) // Reste entre 0,1 et 0,9
+}
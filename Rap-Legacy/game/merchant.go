@@ -0,0 +1,642 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"fmt"                // Lignes de prix
+	"image"              // Zones cliquables des listes
+	"image/color"        // Couleurs des onglets
+	mrand "math/rand/v2" // Tirages des vitrines
+	"time"               // Graine des tirages
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// -----------------
+// Onglets du marchand
+// -----------------
+
+// Onglets du menu du marchand
+const (
+	MerchantTabBuy     = iota // Articles en vente
+	MerchantTabSell           // Vendre ses objets
+	MerchantTabBuyback        // Racheter ce qu'on vient de vendre
+)
+
+var merchantTabNames = []string{"Acheter", "Vendre", "Rachat"}
+
+// Part du prix d'un objet que le marchand paie quand on le lui vend (en %)
+const merchantSellPercent = 40
+
+// Nombre de ventes que le marchand garde de côté pendant la visite
+const buybackSize = 5
+
+// Lignes visibles dans les listes du marchand
+const merchantListRows = 7
+
+// Coin haut gauche du texte, à l'intérieur du panneau
+const merchantX, merchantY = 420, 400
+
+// Quantité maximale d'un article acheté en une fois
+const maxBuyQuantity = 10
+
+// Couleurs des articles trop chers et épuisés
+var (
+	tooExpensiveColor = color.RGBA{200, 80, 80, 255}
+	soldOutColor      = color.RGBA{120, 120, 120, 255}
+)
+
+// soldItem est un objet vendu pendant la visite, rachetable au prix de vente
+type soldItem struct {
+	Item  Item
+	Price int
+}
+
+// ItemSellPrice retourne ce que le marchand paie pour un exemplaire (0 : il n'en veut pas)
+func ItemSellPrice(it Item) int {
+	d, ok := LookupItem(it.ID)
+	if !ok {
+		return 0
+	}
+	return d.Price * rarityPriceFactor[it.Rarity] / 100 * merchantSellPercent / 100
+}
+
+// -----------------
+// Vitrine du marchand
+// -----------------
+
+// MerchantState est l'état d'un marchand enregistré dans la save
+type MerchantState struct {
+	Stock     map[string]int  `json:"stock,omitempty"`  // Exemplaires restants des articles à stock limité (voir ShopEntry.Key)
+	Offers    map[string]Item `json:"offers,omitempty"` // Exemplaires en vitrine des articles tirés au sort
+	RestockAt GameClock       `json:"restock_at"`       // Heure du prochain réassort
+
+	// Marchandage du jour (remis à zéro chaque jour, voir haggle.go)
+	Day     int            `json:"day,omitempty"`     // Jour des remises et de la colère
+	Haggled map[string]int `json:"haggled,omitempty"` // Remises obtenues en marchandant (en %), par article
+	Angry   bool           `json:"angry,omitempty"`   // Marchandage raté : prix majorés et plus de marchandage
+}
+
+// spawnMerchants place les marchands de la map et reprend leur état enregistré
+func (g *Game) spawnMerchants(states map[string]MerchantState) {
+	g.Merchants = nil
+	seed := uint64(time.Now().UnixNano())
+	for i, d := range MerchantDefs() {
+		sprite := d.Sprite
+		if sprite == "" {
+			sprite = "assets/marchand.png"
+		}
+		m := &Merchant{
+			ID:     d.ID,
+			Name:   d.Name,
+			X:      d.X,
+			Y:      d.Y,
+			Shop:   d.Shop,
+			Active: true,
+			Sprite: LoadImage(sprite),
+			rng:    mrand.New(mrand.NewPCG(seed, 0x6d61726368+uint64(i))),
+		}
+		if st, ok := states[d.ID]; ok {
+			m.restore(st)
+		} else {
+			m.Restock(g.clock)
+		}
+		g.Merchants = append(g.Merchants, m)
+	}
+}
+
+// Catalog retourne le catalogue du marchand
+func (m *Merchant) Catalog() ShopDef {
+	return ShopFor(m.Shop)
+}
+
+// Restock remet le stock au complet, renouvelle la vitrine et programme le réassort suivant
+func (m *Merchant) Restock(now GameClock) {
+	shop := m.Catalog()
+	m.State.Stock, m.State.Offers = map[string]int{}, map[string]Item{}
+	for _, e := range shop.Entries {
+		if e.Stock > 0 {
+			m.State.Stock[e.Key()] = e.Stock
+		}
+		if e.Roll {
+			m.State.Offers[e.Key()] = RollItem(e.Item, m.rng, 1)
+		}
+	}
+	hours := shop.RestockHours
+	if hours <= 0 {
+		hours = defaultRestockHours
+	}
+	m.State.RestockAt = now + GameClock(hours)*hourTicks
+}
+
+// restore reprend un état enregistré ; les articles ajoutés au catalogue depuis arrivent au complet
+func (m *Merchant) restore(st MerchantState) {
+	m.State = st
+	if m.State.Stock == nil {
+		m.State.Stock = map[string]int{}
+	}
+	if m.State.Offers == nil {
+		m.State.Offers = map[string]Item{}
+	}
+	for _, e := range m.Catalog().Entries {
+		if _, ok := m.State.Stock[e.Key()]; e.Stock > 0 && !ok {
+			m.State.Stock[e.Key()] = e.Stock
+		}
+		if _, ok := m.State.Offers[e.Key()]; e.Roll && !ok {
+			m.State.Offers[e.Key()] = RollItem(e.Item, m.rng, 1)
+		}
+	}
+}
+
+// Update réassortit le marchand quand l'heure est venue ; un nouveau jour efface les remises et la colère
+func (m *Merchant) Update(now GameClock) {
+	if now >= m.State.RestockAt {
+		m.Restock(now)
+	}
+	if m.State.Day != now.Day() {
+		m.State.Day, m.State.Haggled, m.State.Angry = now.Day(), nil, false
+	}
+}
+
+// Zone retourne la zone d'interaction du marchand (celle de son sprite)
+func (m *Merchant) Zone() image.Rectangle {
+	w, h := 128, 128
+	if m.Sprite != nil {
+		w, h = m.Sprite.Size()
+	}
+	return image.Rect(int(m.X), int(m.Y), int(m.X)+w, int(m.Y)+h)
+}
+
+// merchantNear retourne le marchand à portée du joueur (nil s'il n'y en a pas)
+func (g *Game) merchantNear() *Merchant {
+	if g.player == nil {
+		return nil
+	}
+	playerRect := image.Rect(int(g.player.X), int(g.player.Y), int(g.player.X)+32, int(g.player.Y)+32)
+	for _, m := range g.Merchants {
+		if m.Active && m.Zone().Overlaps(playerRect) {
+			return m
+		}
+	}
+	return nil
+}
+
+// Remaining retourne le stock restant de l'article i, et false si son stock est illimité
+func (m *Merchant) Remaining(i int) (int, bool) {
+	e := m.Catalog().Entries[i]
+	if e.Stock <= 0 {
+		return 0, false
+	}
+	return m.State.Stock[e.Key()], true
+}
+
+// EntryItem retourne l'exemplaire vendu par l'article i : la vitrine, la version de la classe ou l'objet du catalogue
+func (m *Merchant) EntryItem(i int, class string) Item {
+	e := m.Catalog().Entries[i]
+	if it, ok := m.State.Offers[e.Key()]; e.Roll && ok {
+		return it
+	}
+	if e.ClassTag != "" {
+		if d, ok := ItemForClass(e.ClassTag, class); ok {
+			return Item{ID: d.ID}
+		}
+	}
+	return Item{ID: ItemID(e.Item)}
+}
+
+// EntryPrice retourne le prix unitaire de l'article i ; celui d'une vitrine dépend de sa rareté
+func (m *Merchant) EntryPrice(i int, it Item) int {
+	e := m.Catalog().Entries[i]
+	price := e.Price
+	if d, ok := LookupItem(it.ID); ok && price == 0 {
+		price = d.Price
+	}
+	if e.Roll {
+		price = price * rarityPriceFactor[it.Rarity] / 100
+	}
+	return price
+}
+
+// openMerchant ouvre le menu d'un marchand sur l'onglet d'achat
+func (g *Game) openMerchant(m *Merchant) {
+	g.state = StateMerchantMenu
+	g.Merchant = m
+	g.merchantTab = MerchantTabBuy
+	g.SelectedMerchantIndex = 0
+	g.buyQty = 1
+	g.sellConfirm = false
+	g.haggle = nil
+}
+
+// closeMerchant quitte le marchand : les ventes ne sont plus rachetables
+func (g *Game) closeMerchant() {
+	g.state = StatePlaying
+	g.Merchant = nil
+	g.buyback = nil
+	g.sellConfirm = false
+	g.haggle = nil
+	g.saveProgress()
+}
+
+// updateMerchantTabs gère le changement d'onglet (Tab) et l'onglet ouvert
+func (g *Game) updateMerchantTabs() {
+	if g.sellConfirm {
+		g.updateSellConfirm()
+		return
+	}
+	if g.haggle != nil {
+		g.updateHaggle()
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.merchantTab = (g.merchantTab + 1) % len(merchantTabNames)
+		g.SelectedMerchantIndex = 0
+		g.buyQty = 1
+	}
+
+	switch g.merchantTab {
+	case MerchantTabSell:
+		g.updateSellTab()
+	case MerchantTabBuyback:
+		g.updateBuybackTab()
+	default:
+		g.updateBuyTab()
+	}
+}
+
+// merchantListFirst retourne la première ligne affichée d'une liste de n lignes (la sélection reste au milieu)
+func (g *Game) merchantListFirst(n int) int {
+	return max(0, min(g.SelectedMerchantIndex-merchantListRows/2, n-merchantListRows))
+}
+
+// merchantRowRect retourne la zone cliquable d'une ligne affichée
+func merchantRowRect(row int) image.Rectangle {
+	ly := merchantY + 50 + row*40
+	return image.Rect(merchantX-30, ly-30, merchantX+900, ly+10)
+}
+
+// updateMerchantList déplace la sélection dans une liste de n lignes (flèches, molette, clic). Retourne true si la
+// ligne sélectionnée est validée : Entrée, ou clic sur la ligne déjà sélectionnée.
+func (g *Game) updateMerchantList(n int) bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		g.SelectedMerchantIndex--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		g.SelectedMerchantIndex++
+	}
+	if _, dy := ebiten.Wheel(); dy > 0 {
+		g.SelectedMerchantIndex--
+	} else if dy < 0 {
+		g.SelectedMerchantIndex++
+	}
+
+	activate := inpututil.IsKeyJustPressed(ebiten.KeyEnter)
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		p := image.Pt(ebiten.CursorPosition())
+		first := g.merchantListFirst(n)
+		for row := 0; row < merchantListRows && first+row < n; row++ {
+			if p.In(merchantRowRect(row)) {
+				activate = first+row == g.SelectedMerchantIndex
+				g.SelectedMerchantIndex = first + row
+				break
+			}
+		}
+	}
+	g.SelectedMerchantIndex = max(min(g.SelectedMerchantIndex, n-1), 0)
+	return activate && n > 0
+}
+
+// -----------------
+// Achat
+// -----------------
+
+// buyLimit retourne la quantité maximale achetable en une fois de l'article i (une seule vitrine à la fois, pas plus
+// que le stock)
+func (g *Game) buyLimit(i int) int {
+	limit := maxBuyQuantity
+	if g.Merchant.Catalog().Entries[i].Roll {
+		limit = 1
+	}
+	if left, limited := g.Merchant.Remaining(i); limited {
+		limit = min(limit, left)
+	}
+	return limit
+}
+
+// ownedCount retourne le nombre d'exemplaires possédés d'un objet, sac et équipement compris
+func (g *Game) ownedCount(id string) int {
+	n := 0
+	if g.Inventaire != nil {
+		n = g.Inventaire.Count(id)
+	}
+	if g.player != nil {
+		for _, it := range g.player.Equipment {
+			if it.ID == id {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// updateBuyTab : Haut/Bas choisit l'article, Gauche/Droite la quantité, Entrée achète
+func (g *Game) updateBuyTab() {
+	if g.Merchant == nil {
+		return
+	}
+	n := len(g.Merchant.Catalog().Entries)
+	prev := g.SelectedMerchantIndex
+	activate := g.updateMerchantList(n)
+	if n == 0 {
+		return
+	}
+	i := g.SelectedMerchantIndex
+	if i != prev {
+		g.buyQty = 1
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		g.buyQty--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		g.buyQty++
+	}
+	g.buyQty = max(min(g.buyQty, g.buyLimit(i)), 1)
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		g.openHaggle()
+		return
+	}
+	if activate {
+		g.buy(i, g.buyQty)
+	}
+}
+
+// buy achète qty fois l'article i
+func (g *Game) buy(i, qty int) {
+	m := g.Merchant
+	e := m.Catalog().Entries[i]
+	it := m.EntryItem(i, g.PlayerClass)
+	total := g.buyPrice(i, it) * qty
+	if left, limited := m.Remaining(i); limited && left < qty {
+		AddNotification("Épuisé ! Reviens après le réassort.")
+		return
+	}
+	if g.Money < total {
+		AddNotification("Pas assez d'argent !")
+		return
+	}
+	if e.Followers == 0 && g.Inventaire == nil {
+		return
+	}
+	g.Money -= total
+	if _, limited := m.Remaining(i); limited {
+		m.State.Stock[e.Key()] -= qty
+	}
+
+	label := e.Label(it)
+	switch {
+	case e.Followers > 0:
+		g.Followers += e.Followers * qty
+	case e.Roll:
+		// L'exemplaire acheté est remplacé par un nouveau tirage
+		g.Inventaire.Add(it)
+		m.State.Offers[e.Key()] = RollItem(e.Item, m.rng, 1)
+	default:
+		g.Inventaire.Items = g.Inventaire.Items.Add(it.ID, qty)
+	}
+	if qty > 1 {
+		label += fmt.Sprintf(" (x%d)", qty)
+	}
+	AddNotification("Tu as acheté : " + label)
+}
+
+// -----------------
+// Vente
+// -----------------
+
+// updateSellTab : Entrée (ou clic) propose de vendre un exemplaire de la pile sélectionnée
+func (g *Game) updateSellTab() {
+	if g.Inventaire == nil {
+		return
+	}
+	if !g.updateMerchantList(len(g.Inventaire.Items)) {
+		return
+	}
+	it := g.Inventaire.Items[g.SelectedMerchantIndex].Item
+	if ItemSellPrice(it) <= 0 {
+		AddNotification("Le marchand ne veut pas de " + it.Name())
+		return
+	}
+	g.sellConfirm = true
+}
+
+// updateSellConfirm : Entrée confirme la vente, Échap l'annule
+func (g *Game) updateSellConfirm() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.sellConfirm = false
+		return
+	}
+	if !inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return
+	}
+	g.sellConfirm = false
+	i := g.SelectedMerchantIndex
+	if g.Inventaire == nil || i >= len(g.Inventaire.Items) {
+		return
+	}
+	it := g.Inventaire.Items[i].Item
+	price := ItemSellPrice(it)
+	g.Inventaire.removeAt(i)
+	g.Money += price
+
+	// Garde la vente de côté ; la plus ancienne part quand la liste est pleine
+	g.buyback = append([]soldItem{{Item: it, Price: price}}, g.buyback...)
+	if len(g.buyback) > buybackSize {
+		g.buyback = g.buyback[:buybackSize]
+	}
+	AddNotification(fmt.Sprintf("Vendu : %s (+%d$)", it.Label(), price))
+}
+
+// updateBuybackTab : Entrée (ou clic) rachète la vente sélectionnée au prix où elle a été vendue
+func (g *Game) updateBuybackTab() {
+	if !g.updateMerchantList(len(g.buyback)) || g.Inventaire == nil {
+		return
+	}
+	i := g.SelectedMerchantIndex
+	sold := g.buyback[i]
+	if g.Money < sold.Price {
+		AddNotification("Pas assez d'argent !")
+		return
+	}
+	g.Money -= sold.Price
+	g.Inventaire.Add(sold.Item)
+	g.buyback = append(g.buyback[:i], g.buyback[i+1:]...)
+	AddNotification("Racheté : " + sold.Item.Label())
+}
+
+// -----------------
+// Affichage des onglets
+// -----------------
+
+// drawMerchantTabs dessine les onglets et la liste de l'onglet ouvert
+func (g *Game) drawMerchantTabs(screen *ebiten.Image) {
+	face := g.fontSmall
+	if face == nil {
+		face = basicfont.Face7x13
+	}
+	x, y := merchantX, merchantY
+	tabX := x
+	for i, name := range merchantTabNames {
+		var col color.Color = color.RGBA{150, 150, 150, 255}
+		label := name
+		if i == g.merchantTab {
+			col = color.RGBA{255, 255, 0, 255}
+			label = "[" + name + "]"
+		}
+		text.Draw(screen, label, face, tabX, y-60, col)
+		tabX += text.BoundString(face, label).Dx() + 40
+	}
+
+	hint := "Entrée / clic : choisir   Tab : onglets   ESC : quitter"
+	switch g.merchantTab {
+	case MerchantTabSell:
+		g.drawSellTab(screen, face, x, y)
+	case MerchantTabBuyback:
+		g.drawBuybackTab(screen, face, x, y)
+	default:
+		if g.haggle != nil {
+			g.drawHaggle(screen, face, x, y)
+			hint = "Entrée / clic : répondre   ESC : renoncer"
+			break
+		}
+		g.drawBuyTab(screen, face, x, y)
+		hint = "Entrée : acheter   Gauche/Droite : quantité   H : marchander   Tab : onglets   ESC : quitter"
+	}
+	text.Draw(screen, hint, face, x, y+(merchantListRows+3)*40, color.White)
+}
+
+// merchantLine est une ligne d'une liste du marchand
+type merchantLine struct {
+	text string
+	col  color.Color
+}
+
+// drawMerchantList dessine une liste avec la sélection, en faisant défiler autour d'elle
+func (g *Game) drawMerchantList(screen *ebiten.Image, face font.Face, x, y int, lines []merchantLine) {
+	first := g.merchantListFirst(len(lines))
+	for row := 0; row < merchantListRows && first+row < len(lines); row++ {
+		i := first + row
+		ly := y + 50 + row*40
+		if i == g.SelectedMerchantIndex {
+			text.Draw(screen, "▶", face, x-30, ly, color.RGBA{255, 255, 0, 255})
+		}
+		text.Draw(screen, lines[i].text, face, x, ly, lines[i].col)
+	}
+}
+
+// drawBuyTab liste le catalogue : prix, exemplaires possédés, et le détail de l'article sélectionné
+func (g *Game) drawBuyTab(screen *ebiten.Image, face font.Face, x, y int) {
+	m := g.Merchant
+	if m == nil {
+		return
+	}
+	shop := m.Catalog()
+	title := fmt.Sprintf("=== %s (argent : %d$) ===   Réassort dans %dh", shop.Name, g.Money, g.clock.HoursUntil(m.State.RestockAt))
+	if m.State.Angry {
+		title += "   (fâché)"
+	}
+	text.Draw(screen, title, face, x, y, color.White)
+	if len(shop.Entries) == 0 {
+		text.Draw(screen, "Rien à vendre pour l'instant.", face, x, y+50, color.White)
+		return
+	}
+	var lines []merchantLine
+	for i, e := range shop.Entries {
+		it := m.EntryItem(i, g.PlayerClass)
+		price := g.buyPrice(i, it)
+		label := fmt.Sprintf("%s - %d$", e.Label(it), price)
+		if e.Followers == 0 {
+			label += fmt.Sprintf("   (possédé : %d)", g.ownedCount(it.ID))
+		}
+		col := it.Rarity.Color()
+		if price > g.Money {
+			col = tooExpensiveColor
+		}
+		if left, limited := m.Remaining(i); limited && left == 0 {
+			label += "   épuisé"
+			col = soldOutColor
+		} else if limited {
+			label += fmt.Sprintf("   stock : %d", left)
+		}
+		lines = append(lines, merchantLine{label, col})
+	}
+	g.drawMerchantList(screen, face, x, y, lines)
+
+	// Quantité, total et détail de l'article sélectionné
+	i := min(g.SelectedMerchantIndex, len(shop.Entries)-1)
+	it := m.EntryItem(i, g.PlayerClass)
+	qty := max(min(g.buyQty, g.buyLimit(i)), 1)
+	total := g.buyPrice(i, it) * qty
+	detail := fmt.Sprintf("Quantité : %d - total %d$", qty, total)
+	if d, ok := LookupItem(it.ID); ok && d.Description != "" {
+		detail += "   " + d.Description
+	}
+	if affixes := it.AffixText(); affixes != "" {
+		detail += "   " + affixes
+	}
+	col := color.Color(color.RGBA{255, 200, 80, 255})
+	if total > g.Money {
+		col = tooExpensiveColor
+	}
+	text.Draw(screen, detail, face, x, y+(merchantListRows+1)*40+10, col)
+
+	// Ajustements du prix : stock, réputation, charisme, marchandage
+	if mods := g.priceModifiers(i); mods != (PriceModifiers{}) {
+		line := fmt.Sprintf("Prix de base %d$ : %s", m.EntryPrice(i, it), mods)
+		text.Draw(screen, line, face, x, y+(merchantListRows+2)*40+5, color.RGBA{180, 180, 180, 255})
+	}
+}
+
+// drawSellTab liste l'inventaire avec le prix de reprise
+func (g *Game) drawSellTab(screen *ebiten.Image, face font.Face, x, y int) {
+	text.Draw(screen, fmt.Sprintf("=== Vendre (argent : %d$) ===", g.Money), face, x, y, color.White)
+	if g.Inventaire == nil || len(g.Inventaire.Items) == 0 {
+		text.Draw(screen, "Ton sac est vide.", face, x, y+50, color.White)
+		return
+	}
+	var lines []merchantLine
+	for _, st := range g.Inventaire.Items {
+		label := st.Label()
+		if st.Count > 1 {
+			label += fmt.Sprintf(" x%d", st.Count)
+		}
+		if price := ItemSellPrice(st.Item); price > 0 {
+			label += fmt.Sprintf(" - %d$", price)
+		} else {
+			label += " - invendable"
+		}
+		lines = append(lines, merchantLine{label, st.Rarity.Color()})
+	}
+	g.drawMerchantList(screen, face, x, y, lines)
+
+	if g.sellConfirm && g.SelectedMerchantIndex < len(g.Inventaire.Items) {
+		it := g.Inventaire.Items[g.SelectedMerchantIndex].Item
+		prompt := fmt.Sprintf("Vendre %s pour %d$ ? Entrée : oui   ESC : non", it.Label(), ItemSellPrice(it))
+		text.Draw(screen, prompt, face, x, y+(merchantListRows+1)*40+10, color.RGBA{255, 200, 80, 255})
+	}
+}
+
+// drawBuybackTab liste les ventes de la visite
+func (g *Game) drawBuybackTab(screen *ebiten.Image, face font.Face, x, y int) {
+	text.Draw(screen, fmt.Sprintf("=== Rachat (argent : %d$) ===", g.Money), face, x, y, color.White)
+	if len(g.buyback) == 0 {
+		text.Draw(screen, "Rien à racheter.", face, x, y+50, color.White)
+		return
+	}
+	var lines []merchantLine
+	for _, sold := range g.buyback {
+		lines = append(lines, merchantLine{fmt.Sprintf("%s - %d$", sold.Item.Label(), sold.Price), sold.Item.Rarity.Color()})
+	}
+	g.drawMerchantList(screen, face, x, y, lines)
+}
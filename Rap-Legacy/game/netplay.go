@@ -0,0 +1,301 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// -----------------
+// Contrôleurs réseau
+// -----------------
+
+// NetLocalController est le joueur de cette machine : il choisit au clavier et envoie son attaque
+type NetLocalController struct {
+	*HumanController
+	sess *NetSession
+}
+
+func (n *NetLocalController) ChooseMove(b *Battle, side Side) (int, bool) {
+	move, ok := n.HumanController.ChooseMove(b, side)
+	if ok {
+		if err := n.sess.SendMove(b.combat.Turn, side, move); err != nil {
+			n.sess.fail(ErrNetDisconnected)
+		}
+	}
+	return move, ok
+}
+
+// NetRemoteController attend l'attaque envoyée par l'adversaire (lockstep)
+// et surveille la session : déconnexion et désynchronisation interrompent le combat.
+type NetRemoteController struct {
+	sess *NetSession
+}
+
+func (n *NetRemoteController) ChooseMove(b *Battle, side Side) (int, bool) {
+	move, ok := n.sess.RemoteMove(b.combat.Turn, side)
+	if ok && !b.combat.CanUse(side, move) {
+		// Attaque en recharge, trop chère ou inconnue de sa classe : client modifié ou désynchronisé
+		n.sess.Reject(fmt.Sprintf("attaque %d interdite au tour %d", move, b.combat.Turn))
+		return 0, false
+	}
+	return move, ok
+}
+
+func (n *NetRemoteController) Tick(b *Battle) {
+	err := n.sess.Poll()
+	if err == nil || b.state == BattleAborted {
+		return
+	}
+	finished := b.state == BattleVictory || b.state == BattleDefeat
+	switch {
+	case finished && err == ErrNetDisconnected:
+		// L'adversaire a quitté l'écran de fin : rien à signaler
+	case err == ErrNetDisconnected:
+		b.Abort("Adversaire déconnecté : victoire par forfait", n.sess.Local.String())
+	default:
+		log.Println("Erreur réseau:", err)
+		b.Abort("Combat annulé : "+err.Error(), "")
+	}
+}
+
+func (n *NetRemoteController) TurnEnded(b *Battle) {
+	if err := n.sess.RecordHash(b.combat.Turn, b.combat.Hash()); err != nil {
+		n.sess.fail(ErrNetDisconnected)
+	}
+}
+
+// NewNetBattle crée le combat d'une session : l'hôte joue à gauche, le client à droite
+func NewNetBattle(sess *NetSession) *Battle {
+	b := newVersusBattle(sess.Seed, sess.Profiles[SidePlayer], sess.Profiles[SideEnemy])
+	b.controllers[sess.Local] = &NetLocalController{HumanController: NewHumanController(KeysArrow), sess: sess}
+	b.controllers[sess.Local.Other()] = &NetRemoteController{sess: sess}
+	return b
+}
+
+// -----------------
+// Salon réseau (héberger / rejoindre)
+// -----------------
+
+// netResult est le résultat de la connexion, lancée en tâche de fond
+type netResult struct {
+	sess *NetSession
+	err  error
+}
+
+// Ouvre le salon réseau
+func (g *Game) openNetwork() {
+	saves, err := LoadAllSaves()
+	if err != nil {
+		saves = []Save{}
+	}
+	g.netSaves = saves
+	g.netPick = 0
+	g.netJoin = false
+	if g.netAddr == "" {
+		g.netAddr = "127.0.0.1:" + netDefaultPort
+	}
+	g.netStatus = ""
+	g.state = StateNetwork
+}
+
+// netPort retourne le port de l'adresse saisie (utilisé pour héberger)
+func netPort(addr string) string {
+	if _, port, err := net.SplitHostPort(addr); err == nil && port != "" {
+		return port
+	}
+	return netDefaultPort
+}
+
+// startNetwork héberge ou rejoint une partie sans bloquer le jeu
+func (g *Game) startNetwork() {
+	profile := g.netSaves[g.netPick]
+	done := make(chan netResult, 1)
+	g.netPending = done
+
+	if g.netJoin {
+		addr := g.netAddr
+		ctx, cancel := context.WithCancel(context.Background())
+		g.netCancel = cancel
+		g.netStatus = "Connexion à " + addr + "..."
+		go func() {
+			sess, err := JoinSession(ctx, addr, profile)
+			done <- netResult{sess, err}
+		}()
+		return
+	}
+
+	ln, err := net.Listen("tcp", ":"+netPort(g.netAddr))
+	if err != nil {
+		g.netPending = nil
+		g.netStatus = "Impossible d'héberger : " + err.Error()
+		return
+	}
+	g.netListener = ln
+	g.netStatus = "En attente d'un adversaire sur le port " + netPort(g.netAddr) + "..."
+	go func() {
+		sess, err := HostSession(ln, profile)
+		ln.Close() // Un seul adversaire par partie
+		done <- netResult{sess, err}
+	}()
+}
+
+// cancelNetwork arrête d'attendre un adversaire. Une session établie entre-temps est fermée dès son arrivée.
+func (g *Game) cancelNetwork() {
+	if g.netListener != nil {
+		g.netListener.Close()
+		g.netListener = nil
+	}
+	if g.netCancel != nil {
+		g.netCancel()
+		g.netCancel = nil
+	}
+	if pending := g.netPending; pending != nil {
+		go func() {
+			if res := <-pending; res.sess != nil {
+				res.sess.Close()
+			}
+		}()
+	}
+	g.netPending = nil
+	g.netStatus = ""
+}
+
+func (g *Game) updateNetwork() {
+	// Combat en cours
+	if g.netBattle != nil {
+		// ESC pendant le combat : abandon (l'adversaire gagne par forfait)
+		st := g.netBattle.State()
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && st != BattleVictory && st != BattleDefeat && st != BattleAborted {
+			g.netBattle.Abort("Tu as abandonné", g.netSession.Local.Other().String())
+			g.netBattle.exitRequested = true
+		}
+		g.netBattle.Update()
+		if g.netBattle.IsOver() {
+			if g.netBattle.Winner != "" {
+				winner := SidePlayer
+				if g.netBattle.Winner == SideEnemy.String() {
+					winner = SideEnemy
+				}
+				AddNotification(g.netBattle.combat.Fighter(winner).Name + " remporte le clash !")
+			}
+			g.netSession.Close()
+			g.netSession = nil
+			g.netBattle = nil
+			g.state = StateMenu
+		}
+		return
+	}
+
+	// Connexion en cours
+	if g.netPending != nil {
+		select {
+		case res := <-g.netPending:
+			g.netPending = nil
+			g.netListener = nil
+			if g.netCancel != nil {
+				g.netCancel() // Libère le contexte de la connexion
+				g.netCancel = nil
+			}
+			if res.err != nil {
+				log.Println("Erreur réseau:", res.err)
+				g.netStatus = "Échec : " + res.err.Error()
+				return
+			}
+			g.netSession = res.sess
+			g.netBattle = g.configureBattle(NewNetBattle(res.sess))
+			g.netStatus = ""
+		default:
+			if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+				g.cancelNetwork()
+			}
+		}
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || len(g.netSaves) == 0 {
+		if len(g.netSaves) == 0 {
+			AddNotification("Crée au moins une sauvegarde pour jouer en réseau !")
+		}
+		g.state = StateMenu
+		return
+	}
+
+	// Choix du perso
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
+		g.netPick = (g.netPick + len(g.netSaves) - 1) % len(g.netSaves)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
+		g.netPick = (g.netPick + 1) % len(g.netSaves)
+	}
+	// Héberger ou rejoindre
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.netJoin = !g.netJoin
+	}
+	// Saisie de l'adresse
+	for _, r := range ebiten.InputChars() {
+		if strings.ContainsRune("0123456789.:abcdefghijklmnopqrstuvwxyz-", r) && len(g.netAddr) < 40 {
+			g.netAddr += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.netAddr) > 0 {
+		g.netAddr = g.netAddr[:len(g.netAddr)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		g.startNetwork()
+	}
+}
+
+func (g *Game) drawNetwork(screen *ebiten.Image) {
+	if g.netBattle != nil {
+		g.netBattle.Draw(screen)
+		return
+	}
+
+	screen.Fill(color.RGBA{10, 20, 40, 255})
+
+	draw := func(s string, x, y int, col color.Color) {
+		if g.fontSmall != nil {
+			text.Draw(screen, s, g.fontSmall, x, y, col)
+		} else {
+			ebitenutil.DebugPrintAt(screen, s, x, y)
+		}
+	}
+
+	draw("=== VERSUS EN RÉSEAU ===", 780, 150, color.RGBA{255, 215, 0, 255})
+
+	mode := "[Héberger]  Rejoindre "
+	if g.netJoin {
+		mode = " Héberger  [Rejoindre]"
+	}
+	draw("Mode (Tab) : "+mode, 300, 250, color.White)
+	addr := "Adresse : " + g.netAddr
+	if !g.netJoin {
+		addr = "Port : " + netPort(g.netAddr) + "   (adresse : " + g.netAddr + ")"
+	}
+	draw(addr, 300, 300, color.White)
+
+	draw("Ton perso (Flèches) :", 300, 380, color.White)
+	for i, s := range g.netSaves {
+		line := fmt.Sprintf("  %s - %s (ego %d)", s.Name, s.Class, s.Ego)
+		c := color.Color(color.RGBA{160, 160, 160, 255})
+		if i == g.netPick {
+			line = "> " + line[2:]
+			c = color.White
+		}
+		draw(line, 300, 430+i*40, c)
+	}
+
+	if g.netStatus != "" {
+		draw(g.netStatus, 300, 820, color.RGBA{255, 200, 0, 255})
+	}
+	draw("Entrée pour lancer, ESC pour revenir", 300, 900, color.RGBA{200, 200, 200, 255})
+}
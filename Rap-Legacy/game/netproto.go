@@ -0,0 +1,341 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"bufio"         // Pour lire les messages ligne par ligne
+	"context"       // Pour annuler une connexion en cours
+	"encoding/json" // Les messages sont des objets JSON, un par ligne
+	"errors"        // Pour les erreurs du protocole
+	"fmt"           // Pour formater les erreurs
+	"net"           // Connexion TCP
+	"sync"          // Pour protéger l'écriture et l'erreur de la session
+	"time"          // Pour la seed et les délais
+)
+
+// -----------------
+// Protocole réseau du versus
+// -----------------
+
+// Version du protocole : deux clients de versions différentes refusent de jouer ensemble
+const netProtocolVersion = 1
+
+// Port utilisé par défaut pour héberger une partie
+const netDefaultPort = "7777"
+
+// Délai maximum pour la poignée de main
+const netHandshakeTimeout = 10 * time.Second
+
+// Battement de cœur : chaque client envoie un ping régulièrement, et un adversaire muet trop longtemps est
+// considéré comme déconnecté (câble débranché, Wi-Fi coupé...)
+const (
+	netHeartbeat   = 2 * time.Second
+	netIdleTimeout = 10 * time.Second
+)
+
+// Types de messages échangés
+const (
+	netMsgHello   = "hello"   // Client -> hôte : version + perso
+	netMsgWelcome = "welcome" // Hôte -> client : version + perso de l'hôte + seed
+	netMsgMove    = "move"    // Attaque choisie par un camp pour un tour
+	netMsgHash    = "hash"    // Empreinte de l'état en fin de tour (détection de désynchronisation)
+	netMsgError   = "error"   // Refus ou erreur fatale
+	netMsgBye     = "bye"     // Départ volontaire
+	netMsgPing    = "ping"    // Battement de cœur
+)
+
+// Erreurs remontées par la session
+var (
+	ErrNetDesync       = errors.New("désynchronisation détectée")
+	ErrNetDisconnected = errors.New("adversaire déconnecté")
+	ErrNetProtocol     = errors.New("message invalide")
+)
+
+// NetMessage est un message du protocole (une ligne JSON)
+type NetMessage struct {
+	Type    string `json:"type"`
+	Version int    `json:"version,omitempty"`
+	Profile *Save  `json:"profile,omitempty"` // Perso joué (nom, classe, ego, objets)
+	Seed    uint64 `json:"seed,omitempty"`
+	Turn    int    `json:"turn,omitempty"`
+	Side    Side   `json:"side"`
+	Move    int    `json:"move"`
+	Hash    uint64 `json:"hash,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// netKey identifie une attaque attendue : un tour et un camp
+type netKey struct {
+	turn int
+	side Side
+}
+
+// NetSession est une partie en réseau entre deux clients en lockstep :
+// chaque client n'avance que quand il connaît l'attaque de l'autre.
+type NetSession struct {
+	Local    Side    // Camp joué sur cette machine (hôte = gauche, client = droite)
+	Seed     uint64  // Seed partagée du combat
+	Profiles [2]Save // Persos des deux camps
+
+	conn  net.Conn
+	wmu   sync.Mutex // Protège les écritures
+	enc   *json.Encoder
+	inbox chan NetMessage
+	done  chan struct{} // Fermé par Close : arrête les tâches de fond
+	once  sync.Once     // Close n'agit qu'une fois
+
+	emu sync.Mutex // Protège err
+	err error      // Première erreur de lecture (déconnexion...)
+
+	moves        map[netKey]int // Attaques reçues de l'adversaire
+	localHashes  map[int]uint64 // Empreintes calculées ici, par tour
+	remoteHashes map[int]uint64 // Empreintes reçues, par tour
+}
+
+// newNetSession prépare une session sur une connexion établie
+func newNetSession(conn net.Conn, local Side) *NetSession {
+	return &NetSession{
+		Local:        local,
+		conn:         conn,
+		enc:          json.NewEncoder(conn),
+		inbox:        make(chan NetMessage, 64),
+		done:         make(chan struct{}),
+		moves:        map[netKey]int{},
+		localHashes:  map[int]uint64{},
+		remoteHashes: map[int]uint64{},
+	}
+}
+
+// HostSession attend un client sur le listener, vérifie sa version et lui envoie la seed
+func HostSession(ln net.Listener, profile Save) (*NetSession, error) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return nil, err
+	}
+	s := newNetSession(conn, SidePlayer)
+	reader := bufio.NewReader(conn)
+
+	conn.SetDeadline(time.Now().Add(netHandshakeTimeout))
+	hello, err := readNetMessage(reader)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if hello.Type != netMsgHello || hello.Profile == nil {
+		conn.Close()
+		return nil, fmt.Errorf("message inattendu : %q", hello.Type)
+	}
+	if hello.Version != netProtocolVersion {
+		s.send(NetMessage{Type: netMsgError, Error: fmt.Sprintf("version %d attendue", netProtocolVersion)})
+		conn.Close()
+		return nil, fmt.Errorf("version du client incompatible : %d", hello.Version)
+	}
+
+	s.Seed = uint64(time.Now().UnixNano())
+	s.Profiles = [2]Save{profile, *hello.Profile}
+	if err := s.send(NetMessage{Type: netMsgWelcome, Version: netProtocolVersion, Profile: &profile, Seed: s.Seed}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	s.start(reader)
+	return s, nil
+}
+
+// JoinSession se connecte à un hôte et récupère son perso et la seed. Annuler ctx interrompt la connexion et la
+// poignée de main.
+func JoinSession(ctx context.Context, addr string, profile Save) (*NetSession, error) {
+	dialer := net.Dialer{Timeout: netHandshakeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	s := newNetSession(conn, SideEnemy)
+	reader := bufio.NewReader(conn)
+
+	conn.SetDeadline(time.Now().Add(netHandshakeTimeout))
+	if err := s.send(NetMessage{Type: netMsgHello, Version: netProtocolVersion, Profile: &profile}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	welcome, err := readNetMessage(reader)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if welcome.Type == netMsgError {
+		conn.Close()
+		return nil, errors.New(welcome.Error)
+	}
+	if welcome.Type != netMsgWelcome || welcome.Profile == nil || welcome.Version != netProtocolVersion {
+		conn.Close()
+		return nil, fmt.Errorf("réponse inattendue de l'hôte : %q (version %d)", welcome.Type, welcome.Version)
+	}
+	conn.SetDeadline(time.Time{})
+
+	s.Seed = welcome.Seed
+	s.Profiles = [2]Save{*welcome.Profile, profile}
+	s.start(reader)
+	return s, nil
+}
+
+// start lance la lecture des messages et le battement de cœur, une fois la poignée de main terminée
+func (s *NetSession) start(r *bufio.Reader) {
+	go s.readLoop(r)
+	go s.heartbeat()
+}
+
+// readNetMessage lit une ligne JSON
+func readNetMessage(r *bufio.Reader) (NetMessage, error) {
+	var msg NetMessage
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return msg, err
+	}
+	err = json.Unmarshal(line, &msg)
+	return msg, err
+}
+
+// readLoop lit les messages en tâche de fond jusqu'à la déconnexion, un silence trop long ou Close
+func (s *NetSession) readLoop(r *bufio.Reader) {
+	defer close(s.inbox)
+	for {
+		s.conn.SetReadDeadline(time.Now().Add(netIdleTimeout))
+		msg, err := readNetMessage(r)
+		if err != nil {
+			s.fail(ErrNetDisconnected)
+			return
+		}
+		if msg.Type == netMsgPing {
+			continue // Il a seulement repoussé le délai
+		}
+		select {
+		case s.inbox <- msg:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// heartbeat envoie un ping régulièrement jusqu'à Close, pour que l'adversaire sache qu'on est toujours là
+func (s *NetSession) heartbeat() {
+	ticker := time.NewTicker(netHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.send(NetMessage{Type: netMsgPing}); err != nil {
+				s.fail(ErrNetDisconnected)
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// send écrit un message (sûr depuis plusieurs goroutines)
+func (s *NetSession) send(msg NetMessage) error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(netIdleTimeout)) // Un adversaire qui ne lit plus ne bloque pas le jeu
+	return s.enc.Encode(msg)                                // Encode ajoute le '\n' final
+}
+
+// fail mémorise la première erreur fatale de la session
+func (s *NetSession) fail(err error) {
+	s.emu.Lock()
+	defer s.emu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Reject refuse un message de l'adversaire : la session s'arrête sur une erreur de protocole, et l'adversaire est
+// prévenu
+func (s *NetSession) Reject(reason string) {
+	err := fmt.Errorf("%w : %s", ErrNetProtocol, reason)
+	s.fail(err)
+	s.send(NetMessage{Type: netMsgError, Error: err.Error()})
+}
+
+// Err retourne l'erreur fatale de la session, s'il y en a une
+func (s *NetSession) Err() error {
+	s.emu.Lock()
+	defer s.emu.Unlock()
+	return s.err
+}
+
+// Poll traite les messages reçus depuis le dernier appel (à appeler à chaque tick)
+func (s *NetSession) Poll() error {
+	for {
+		select {
+		case msg, ok := <-s.inbox:
+			if !ok {
+				return s.Err()
+			}
+			s.handle(msg)
+		default:
+			return s.Err()
+		}
+	}
+}
+
+// handle applique un message reçu pendant le combat
+func (s *NetSession) handle(msg NetMessage) {
+	switch msg.Type {
+	case netMsgMove:
+		if msg.Side == s.Local || msg.Move < 0 || msg.Move >= len(moveDefs()) {
+			s.Reject(fmt.Sprintf("attaque invalide reçue : camp %d, attaque %d", msg.Side, msg.Move))
+			return
+		}
+		s.moves[netKey{msg.Turn, msg.Side}] = msg.Move
+	case netMsgHash:
+		s.remoteHashes[msg.Turn] = msg.Hash
+		s.checkHash(msg.Turn)
+	case netMsgError:
+		s.fail(errors.New(msg.Error))
+	case netMsgBye:
+		s.fail(ErrNetDisconnected)
+	}
+}
+
+// checkHash compare les empreintes d'un tour quand les deux sont connues
+func (s *NetSession) checkHash(turn int) {
+	local, okL := s.localHashes[turn]
+	remote, okR := s.remoteHashes[turn]
+	if okL && okR && local != remote {
+		s.fail(fmt.Errorf("%w au tour %d", ErrNetDesync, turn))
+	}
+}
+
+// SendMove envoie l'attaque choisie localement pour un tour
+func (s *NetSession) SendMove(turn int, side Side, move int) error {
+	return s.send(NetMessage{Type: netMsgMove, Turn: turn, Side: side, Move: move})
+}
+
+// RemoteMove retourne l'attaque de l'adversaire pour un tour, si elle est arrivée
+func (s *NetSession) RemoteMove(turn int, side Side) (int, bool) {
+	move, ok := s.moves[netKey{turn, side}]
+	if ok {
+		delete(s.moves, netKey{turn, side})
+	}
+	return move, ok
+}
+
+// RecordHash mémorise l'empreinte locale d'un tour et l'envoie à l'adversaire
+func (s *NetSession) RecordHash(turn int, hash uint64) error {
+	s.localHashes[turn] = hash
+	s.checkHash(turn)
+	return s.send(NetMessage{Type: netMsgHash, Turn: turn, Hash: hash})
+}
+
+// Close prévient l'adversaire, arrête les tâches de fond et ferme la connexion (plusieurs appels sont sans effet)
+func (s *NetSession) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.send(NetMessage{Type: netMsgBye})
+		s.conn.Close()
+	})
+}
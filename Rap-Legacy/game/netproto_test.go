@@ -0,0 +1,213 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// Délai maximum d'attente d'un message dans les tests (la boucle locale répond en quelques millisecondes)
+const netTestTimeout = 5 * time.Second
+
+// netTestProfile retourne un perso de sauvegarde pour les tests
+func netTestProfile(name, class string) Save {
+	return Save{Name: name, Class: class, Ego: 100, Flow: 10, Charisma: 5}
+}
+
+// netPair ouvre une session hôte et une session client reliées en local
+func netPair(t *testing.T) (host, client *NetSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	hosted := make(chan netResult, 1)
+	go func() {
+		sess, err := HostSession(ln, netTestProfile("Hôte", "Lyricistes"))
+		hosted <- netResult{sess, err}
+	}()
+	client, err = JoinSession(context.Background(), ln.Addr().String(), netTestProfile("Client", "Performeurs"))
+	if err != nil {
+		t.Fatal("rejoindre :", err)
+	}
+	res := <-hosted
+	if res.err != nil {
+		client.Close()
+		t.Fatal("héberger :", res.err)
+	}
+	t.Cleanup(func() {
+		res.sess.Close()
+		client.Close()
+	})
+	return res.sess, client
+}
+
+// waitNetErr attend que la session remonte une erreur
+func waitNetErr(t *testing.T, s *NetSession) error {
+	t.Helper()
+	deadline := time.Now().Add(netTestTimeout)
+	for time.Now().Before(deadline) {
+		if err := s.Poll(); err != nil {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("aucune erreur reçue")
+	return nil
+}
+
+// netScriptedController joue le camp local avec l'IA de base et envoie ses attaques, comme NetLocalController
+type netScriptedController struct {
+	ai   *AIController
+	sess *NetSession
+}
+
+func (n *netScriptedController) ChooseMove(b *Battle, side Side) (int, bool) {
+	move, ok := n.ai.ChooseMove(b, side)
+	if ok {
+		if err := n.sess.SendMove(b.combat.Turn, side, move); err != nil {
+			n.sess.fail(ErrNetDisconnected)
+		}
+	}
+	return move, ok
+}
+
+func TestNetHandshake(t *testing.T) {
+	t.Chdir("..")
+	host, client := netPair(t)
+
+	if host.Local != SidePlayer || client.Local != SideEnemy {
+		t.Errorf("camps : hôte %v, client %v", host.Local, client.Local)
+	}
+	if host.Seed != client.Seed {
+		t.Errorf("seeds différentes : %d et %d", host.Seed, client.Seed)
+	}
+	for side, p := range host.Profiles {
+		if q := client.Profiles[side]; p.Name != q.Name || p.Class != q.Class {
+			t.Errorf("camp %d : %s (%s) chez l'hôte, %s (%s) chez le client", side, p.Name, p.Class, q.Name, q.Class)
+		}
+	}
+}
+
+func TestNetMatch(t *testing.T) {
+	t.Chdir("..")
+	host, client := netPair(t)
+
+	battles := [2]*Battle{NewNetBattle(host), NewNetBattle(client)}
+	for i, sess := range []*NetSession{host, client} {
+		battles[i].SetSpeed(SpeedInstant)
+		battles[i].controllers[sess.Local] = &netScriptedController{ai: NewAIController(sess.Seed + uint64(i)), sess: sess}
+	}
+
+	finished := func(b *Battle) bool {
+		return b.State() == BattleVictory || b.State() == BattleDefeat || b.State() == BattleAborted
+	}
+	deadline := time.Now().Add(netTestTimeout)
+	for !finished(battles[0]) || !finished(battles[1]) {
+		if time.Now().After(deadline) {
+			t.Fatalf("combat non terminé : %v / %v au tour %d", battles[0].State(), battles[1].State(), battles[0].combat.Turn)
+		}
+		for _, b := range battles {
+			b.advance(battleInput{})
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+
+	for i, b := range battles {
+		if b.State() == BattleAborted {
+			t.Fatalf("combat %d interrompu : %s", i, b.abortMsg)
+		}
+	}
+	if battles[0].Winner == "" || battles[0].Winner != battles[1].Winner {
+		t.Errorf("gagnants : %q et %q", battles[0].Winner, battles[1].Winner)
+	}
+	if h0, h1 := battles[0].combat.Hash(), battles[1].combat.Hash(); h0 != h1 {
+		t.Errorf("états différents en fin de combat : %x et %x", h0, h1)
+	}
+	for _, sess := range []*NetSession{host, client} {
+		if err := sess.Poll(); err != nil {
+			t.Errorf("erreur de session : %v", err)
+		}
+	}
+}
+
+func TestNetDesync(t *testing.T) {
+	t.Chdir("..")
+	host, client := netPair(t)
+
+	if err := host.RecordHash(1, 0x1234); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RecordHash(1, 0x5678); err != nil {
+		t.Fatal(err)
+	}
+	for _, sess := range []*NetSession{host, client} {
+		if err := waitNetErr(t, sess); !errors.Is(err, ErrNetDesync) {
+			t.Errorf("erreur %v, désynchronisation attendue", err)
+		}
+	}
+}
+
+func TestNetDisconnect(t *testing.T) {
+	t.Chdir("..")
+
+	t.Run("départ", func(t *testing.T) {
+		host, client := netPair(t)
+		client.Close()
+		if err := waitNetErr(t, host); err != ErrNetDisconnected {
+			t.Errorf("erreur %v, déconnexion attendue", err)
+		}
+	})
+
+	t.Run("coupure", func(t *testing.T) {
+		host, client := netPair(t)
+		client.conn.Close() // Sans message d'au revoir
+		if err := waitNetErr(t, host); err != ErrNetDisconnected {
+			t.Errorf("erreur %v, déconnexion attendue", err)
+		}
+	})
+
+	t.Run("combat", func(t *testing.T) {
+		host, client := netPair(t)
+		b := NewNetBattle(host)
+		client.Close()
+		deadline := time.Now().Add(netTestTimeout)
+		for b.State() != BattleAborted && time.Now().Before(deadline) {
+			b.advance(battleInput{})
+			time.Sleep(time.Millisecond)
+		}
+		if b.State() != BattleAborted || b.Winner != SidePlayer.String() {
+			t.Errorf("état %v, gagnant %q : victoire par forfait attendue", b.State(), b.Winner)
+		}
+	})
+}
+
+func TestNetRejectMove(t *testing.T) {
+	t.Chdir("..")
+	host, client := netPair(t)
+	b := NewNetBattle(host)
+	remote := b.controllers[SideEnemy]
+
+	// Le client joue un Performeur : la signature des Lyricistes lui est interdite
+	if err := client.SendMove(b.combat.Turn, SideEnemy, MoveMultiRhymes); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(netTestTimeout)
+	for host.Err() == nil && time.Now().Before(deadline) {
+		host.Poll()
+		if move, ok := remote.ChooseMove(b, SideEnemy); ok {
+			t.Fatalf("attaque %d acceptée", move)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := host.Err(); !errors.Is(err, ErrNetProtocol) {
+		t.Errorf("erreur %v, erreur de protocole attendue", err)
+	}
+	if err := waitNetErr(t, client); err == nil || err == ErrNetDisconnected {
+		t.Errorf("erreur %v côté client, refus attendu", err)
+	}
+}
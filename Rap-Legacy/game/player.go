@@ -6,10 +6,12 @@ import "github.com/hajimehoshi/ebiten/v2"
 type Player struct {
 	X, Y                  float64       // Position du joueur sur l'axe X et Y
 	Ego                   int           // Valeur d'ego du joueur (sa "vie" ou énergie)
+	MaxEgo                int           // Ego maximum (récupération plafonnée à cette valeur)
 	Flow                  int           // Niveau de flow
 	Charisma              int           // Charisme du joueur
 	BonusEgo              int           // Bonus temporaire d'ego pour le prochain combat
 	PendingEnemyEgoDebuff int           // Malus d'ego appliqué à l'ennemi lors du prochain combat
+	Equipment             Equipment     // Objets portés, par emplacement (voir equipment.go)
 	sprite                *ebiten.Image // Image représentant le joueur
 	class                 string        // Classe ou type de joueur
 }
@@ -30,6 +32,7 @@ func NewPlayer(x, y float64, class string) *Player {
 		X:        x,
 		Y:        y,
 		Ego:      100,
+		MaxEgo:   100,
 		Flow:     10,
 		Charisma: 5,
 		class:    class,
@@ -43,18 +46,36 @@ func (p *Player) ResetPosition() {
 	p.Y = SpawnY
 }
 
+// Heal rend de l'ego au joueur sans dépasser son ego max (équipement compris) et retourne l'ego réellement récupéré
+func (p *Player) Heal(amount int) int {
+	before := p.Ego
+	p.Ego += amount
+	if maxEgo := p.Stats().Ego; p.Ego > maxEgo {
+		p.Ego = maxEgo
+	}
+	if p.Ego < before { // Ego déjà au-dessus du max : on ne retire rien
+		p.Ego = before
+	}
+	return p.Ego - before
+}
+
+// IsKO indique si le joueur n'a plus d'ego (combat impossible)
+func (p *Player) IsKO() bool {
+	return p.Ego <= 0
+}
+
 // Fonction Update pour gérer les déplacements du joueur
 func (p *Player) Update() {
-	if ebiten.IsKeyPressed(ebiten.KeyW) { // Monter
+	if isKeyPressed(ebiten.KeyW) { // Monter
 		p.Y -= 2
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyS) { // Descendre
+	if isKeyPressed(ebiten.KeyS) { // Descendre
 		p.Y += 2
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyA) { // Aller à gauche
+	if isKeyPressed(ebiten.KeyA) { // Aller à gauche
 		p.X -= 2
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyD) { // Aller à droite
+	if isKeyPressed(ebiten.KeyD) { // Aller à droite
 		p.X += 2
 	}
 }
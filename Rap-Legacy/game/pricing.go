@@ -0,0 +1,89 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"fmt"     // Description des ajustements
+	"strings" // Assemblage des ajustements
+)
+
+// -----------------
+// Prix d'achat
+// -----------------
+
+// Ajustements des prix d'achat (en %)
+const (
+	scarcityMaxPercent   = 30  // Hausse quand le stock est épuisé : le prix monte à mesure qu'il baisse
+	followersPerPercent  = 200 // Followers pour 1% de remise (réputation)
+	reputationMaxPercent = 15  // Remise maximale due à la réputation
+	charismaBase         = 5   // Charisme de départ : pas de remise
+	charismaMaxPercent   = 20  // Remise maximale due au charisme (1% par point au-dessus de charismaBase)
+	angryPercent         = 25  // Majoration d'un marchand fâché
+	maxDiscountPercent   = 45  // Remise totale maximale : le marchand reprend 40% du prix, il ne doit pas y perdre
+)
+
+// PriceModifiers détaille les ajustements d'un prix d'achat (en %, positifs quand le prix monte)
+type PriceModifiers struct {
+	Supply     int // Stock qui baisse
+	Reputation int // Followers du joueur
+	Charisma   int // Charisme du joueur, équipement compris
+	Haggle     int // Remise obtenue en marchandant
+	Anger      int // Marchand fâché
+}
+
+// Total retourne l'ajustement total
+func (p PriceModifiers) Total() int {
+	return p.Supply + p.Reputation + p.Charisma + p.Haggle + p.Anger
+}
+
+// Apply ajuste un prix de base (remise plafonnée à maxDiscountPercent, jamais moins de 1$)
+func (p PriceModifiers) Apply(base int) int {
+	if base <= 0 {
+		return base
+	}
+	return max(base*(100+max(p.Total(), -maxDiscountPercent))/100, 1)
+}
+
+// String décrit les ajustements ("+10% stock bas, -3% réputation") ; "" s'il n'y en a pas
+func (p PriceModifiers) String() string {
+	var parts []string
+	for _, m := range []struct {
+		name  string
+		value int
+	}{{"stock bas", p.Supply}, {"réputation", p.Reputation}, {"charisme", p.Charisma}, {"marchandage", p.Haggle}, {"marchand fâché", p.Anger}} {
+		if m.value != 0 {
+			parts = append(parts, fmt.Sprintf("%+d%% %s", m.value, m.name))
+		}
+	}
+	if p.Total() < -maxDiscountPercent {
+		parts = append(parts, fmt.Sprintf("remise plafonnée à %d%%", maxDiscountPercent))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// priceModifiers calcule les ajustements de l'article i du marchand ouvert
+func (g *Game) priceModifiers(i int) PriceModifiers {
+	var p PriceModifiers
+	m := g.Merchant
+	e := m.Catalog().Entries[i]
+	if left, limited := m.Remaining(i); limited {
+		p.Supply = scarcityMaxPercent * (e.Stock - min(left, e.Stock)) / e.Stock
+	}
+	p.Reputation = -min(g.Followers/followersPerPercent, reputationMaxPercent)
+	if g.player != nil {
+		p.Charisma = -max(min(g.player.Stats().Charisma-charismaBase, charismaMaxPercent), 0)
+	}
+	p.Haggle = -m.State.Haggled[e.Key()]
+	if m.State.Angry {
+		p.Anger = angryPercent
+	}
+	return p
+}
+
+// buyPrice retourne le prix unitaire de l'article i du marchand ouvert, ajustements compris. Il reste au-dessus du
+// prix de reprise : acheter pour revendre ne rapporte jamais rien.
+func (g *Game) buyPrice(i int, it Item) int {
+	price := g.priceModifiers(i).Apply(g.Merchant.EntryPrice(i, it))
+	if e := g.Merchant.Catalog().Entries[i]; e.Followers == 0 {
+		price = max(price, ItemSellPrice(it)+1)
+	}
+	return price
+}
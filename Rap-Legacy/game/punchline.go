@@ -0,0 +1,172 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	mrand "math/rand/v2" // Générateur reproductible
+	"strings"            // Remplissage des modèles
+	"unicode"            // Nettoyage des mots
+)
+
+// -----------------
+// Générateur de punchlines
+// -----------------
+
+// Fins de phrase qui rabaissent l'adversaire ("t'es ...")
+var punchInsults = []string{
+	"un couplet raté", "un disque rayé", "un son démodé", "un MC périmé",
+	"un rappeur de salon", "une face B sans son", "un vieux brouillon",
+	"un flop à venir", "un beat qui fait fuir", "un refrain à bannir",
+	"un MC sans lendemain", "un featuring en vain", "un couplet de bas de gamme sans refrain",
+	"un texte en plastique", "un son anémique", "un flow soporifique",
+	"un imposteur", "un petit amateur", "un rappeur sans valeur",
+}
+
+// Fins de phrase qui font briller le locuteur ("je suis ...")
+var punchBrags = []string{
+	"un classique gravé", "le roi couronné", "le son le plus streamé",
+	"le patron", "le champion", "la voix de la nation",
+	"l'avenir", "le hit qui va tout conquérir", "le mic qui fait frémir",
+	"le roi du refrain", "le hit de demain", "le patron du terrain",
+	"un flow mythique", "une plume magnifique", "un phénomène scénique",
+	"le meilleur", "un vrai performeur", "la star à toute heure",
+}
+
+// punchTemplate est un modèle de phrase. Emplacements :
+// {opp} nom de l'adversaire, {class} classe du locuteur, {item} objet possédé, {move} attaque,
+// {insult} et {brag} deux fins de phrase qui riment entre elles.
+type punchTemplate struct {
+	Context string // "" (attaque, critique), CtxVictory ou CtxDefeat
+	Text    string
+}
+
+var punchTemplates = []punchTemplate{
+	{"", "{opp}, t'es {insult}, moi je suis {brag} !"},
+	{"", "Face à moi t'es {insult}, sur scène je suis {brag}."},
+	{"", "{move} ! Ça te rappelle que t'es {insult}, et moi {brag} !"},
+	{"", "Un {class} comme moi c'est {brag}, toi t'es {insult}."},
+	{"", "Avec {item} dans la poche je suis {brag}, toi t'es {insult} !"},
+	{"", "Écoute bien {opp} : t'es {insult}, je suis {brag}."},
+	{CtxCrit, "En plein dans le mille : t'es {insult}, je suis {brag} !"},
+	{CtxVictory, "C'est fini {opp}, t'es {insult}, je reste {brag} !"},
+	{CtxVictory, "Rideau : t'étais {insult}, moi {brag}."},
+	{CtxDefeat, "Tu m'as eu... mais demain je redeviens {brag}."},
+	{CtxDefeat, "Profite {opp}, je serai bientôt {brag}."},
+}
+
+// rhymeEndings ramène les terminaisons écrites à un même son (les plus longues d'abord)
+var rhymeEndings = []struct{ suffix, sound string }{
+	{"iques", "ique"}, {"ique", "ique"},
+	{"eurs", "eur"}, {"eure", "eur"}, {"eur", "eur"},
+	{"eint", "ain"}, {"ains", "ain"}, {"ain", "ain"}, {"ins", "ain"}, {"in", "ain"},
+	{"ire", "ir"}, {"irs", "ir"}, {"ir", "ir"},
+	{"ons", "on"}, {"ond", "on"}, {"ont", "on"}, {"on", "on"},
+	{"ées", "é"}, {"ée", "é"}, {"és", "é"}, {"er", "é"}, {"ez", "é"}, {"é", "é"},
+}
+
+// rhymeKey retourne le son final du dernier mot d'une phrase
+func rhymeKey(phrase string) string {
+	words := strings.Fields(strings.ToLower(phrase))
+	if len(words) == 0 {
+		return ""
+	}
+	word := strings.TrimFunc(words[len(words)-1], func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, e := range rhymeEndings {
+		if strings.HasSuffix(word, e.suffix) {
+			return e.sound
+		}
+	}
+	// Son inconnu : la dernière voyelle (ou groupe de voyelles) et ce qui la suit
+	r := []rune(word)
+	i := len(r)
+	for i > 0 && !isVowel(r[i-1]) {
+		i--
+	}
+	for i > 0 && isVowel(r[i-1]) {
+		i--
+	}
+	return string(r[i:])
+}
+
+// PunchlineInput décrit qui parle et dans quelle situation
+type PunchlineInput struct {
+	Opponent string   // Nom de l'adversaire ("" : modèles sans nom)
+	Class    string   // Classe du locuteur ("" : modèles sans classe)
+	Items    []string // Objets possédés par le locuteur
+	Move     int      // Attaque lancée (-1 : aucune ; les défenses et la respiration ne sont pas nommées)
+	Context  string   // "", CtxCrit, CtxVictory ou CtxDefeat
+}
+
+// PunchlineGen fabrique des répliques à partir des modèles et des listes de mots
+type PunchlineGen struct {
+	rng    *mrand.Rand
+	rhymes map[string][2][]string // Fins de phrase par son : [0] rabaissent, [1] font briller
+	sounds []string               // Sons qui ont au moins une fin de chaque sorte
+}
+
+// NewPunchlineGen crée un générateur reproductible (même seed, mêmes répliques)
+func NewPunchlineGen(seed uint64) *PunchlineGen {
+	p := &PunchlineGen{
+		rng:    mrand.New(mrand.NewPCG(seed, seed^0x5deece66d)),
+		rhymes: map[string][2][]string{},
+	}
+	for i, list := range [2][]string{punchInsults, punchBrags} {
+		for _, phrase := range list {
+			key := rhymeKey(phrase)
+			group := p.rhymes[key]
+			group[i] = append(group[i], phrase)
+			p.rhymes[key] = group
+		}
+	}
+	// Ordre fixe des sons pour rester reproductible (on parcourt les listes, pas la map)
+	for _, phrase := range punchInsults {
+		key := rhymeKey(phrase)
+		if len(p.rhymes[key][1]) > 0 && !contains(p.sounds, key) {
+			p.sounds = append(p.sounds, key)
+		}
+	}
+	return p
+}
+
+// Line fabrique une réplique pour la situation
+func (p *PunchlineGen) Line(in PunchlineInput) string {
+	// Modèles de la situation dont tous les emplacements peuvent être remplis
+	var usable []string
+	for _, t := range punchTemplates {
+		if t.Context != in.Context && !(in.Context == CtxCrit && t.Context == "") {
+			continue
+		}
+		if (strings.Contains(t.Text, "{opp}") && in.Opponent == "") ||
+			(strings.Contains(t.Text, "{class}") && in.Class == "") ||
+			(strings.Contains(t.Text, "{item}") && len(in.Items) == 0) ||
+			(strings.Contains(t.Text, "{move}") && in.Move < 0) {
+			continue
+		}
+		if strings.Contains(t.Text, "{move}") && (moveDefs()[in.Move].Kind == KindDefense || moveDefs()[in.Move].Kind == KindRest) {
+			continue
+		}
+		usable = append(usable, t.Text)
+	}
+	if len(usable) == 0 || len(p.sounds) == 0 {
+		return ""
+	}
+
+	// Une rime : une fin qui rabaisse et une qui fait briller, sur le même son
+	group := p.rhymes[p.sounds[p.rng.IntN(len(p.sounds))]]
+	fill := map[string]string{
+		"{insult}": group[0][p.rng.IntN(len(group[0]))],
+		"{brag}":   group[1][p.rng.IntN(len(group[1]))],
+		"{opp}":    in.Opponent,
+		"{class}":  strings.TrimSuffix(in.Class, "s"), // "Lyricistes" -> "Lyriciste"
+	}
+	if len(in.Items) > 0 {
+		fill["{item}"] = in.Items[p.rng.IntN(len(in.Items))]
+	}
+	if in.Move >= 0 {
+		fill["{move}"] = moveDefs()[in.Move].Name
+	}
+
+	line := usable[p.rng.IntN(len(usable))]
+	for slot, word := range fill {
+		line = strings.ReplaceAll(line, slot, word)
+	}
+	return line
+}
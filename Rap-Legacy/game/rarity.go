@@ -0,0 +1,194 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"fmt"                // Description des propriétés
+	"image/color"        // Couleurs des raretés
+	mrand "math/rand/v2" // Tirages reproductibles (seed du combat)
+	"strings"            // Assemblage des descriptions
+)
+
+// -----------------
+// Rareté et propriétés des objets
+// -----------------
+
+// Rarity est la rareté d'un exemplaire d'objet
+type Rarity string
+
+const (
+	RarityCommon    Rarity = ""          // Commun (valeur par défaut : les anciennes saves restent valides)
+	RarityRare      Rarity = "rare"      // Rare : une propriété tirée au sort
+	RarityLegendary Rarity = "legendary" // Légendaire : deux propriétés renforcées
+)
+
+// String retourne le nom affiché de la rareté
+func (r Rarity) String() string {
+	switch r {
+	case RarityRare:
+		return "rare"
+	case RarityLegendary:
+		return "légendaire"
+	}
+	return "commun"
+}
+
+// Color retourne la couleur des noms d'objets de cette rareté
+func (r Rarity) Color() color.Color {
+	switch r {
+	case RarityRare:
+		return color.RGBA{90, 160, 255, 255}
+	case RarityLegendary:
+		return color.RGBA{255, 160, 40, 255}
+	}
+	return color.White
+}
+
+// Poids des raretés au tirage d'un équipement
+var rarityWeights = []struct {
+	Rarity Rarity
+	Weight int
+}{
+	{RarityCommon, 70},
+	{RarityRare, 25},
+	{RarityLegendary, 5},
+}
+
+// Prix d'un exemplaire selon sa rareté, en % du prix d'un exemplaire commun
+var rarityPriceFactor = map[Rarity]int{RarityCommon: 100, RarityRare: 200, RarityLegendary: 400}
+
+// Nombre de propriétés tirées selon la rareté
+var rarityAffixes = map[Rarity]int{RarityRare: 1, RarityLegendary: 2}
+
+// Propriétés possibles (les stats de Stats, voir equipment.go)
+const (
+	AffixEgo           = "ego"
+	AffixFlow          = "flow"
+	AffixCharisma      = "charisma"
+	AffixCritPunchline = "crit_punchline"
+	AffixCritFlow      = "crit_flow"
+	AffixCritHeavy     = "crit_heavy"
+)
+
+// Valeurs tirées pour chaque propriété (bornes comprises)
+var affixPool = []struct {
+	Stat     string
+	Min, Max int
+}{
+	{AffixEgo, 5, 15},
+	{AffixFlow, 1, 3},
+	{AffixCharisma, 2, 5},
+	{AffixCritPunchline, 3, 6},
+	{AffixCritFlow, 3, 6},
+	{AffixCritHeavy, 3, 6},
+}
+
+// Affix est une propriété tirée au sort sur un équipement
+type Affix struct {
+	Stat   string `json:"stat"`   // Voir Affix...
+	Amount int    `json:"amount"` // Valeur du bonus
+}
+
+// Stats retourne le bonus de la propriété
+func (a Affix) Stats() Stats {
+	switch a.Stat {
+	case AffixEgo:
+		return Stats{Ego: a.Amount}
+	case AffixFlow:
+		return Stats{Flow: a.Amount}
+	case AffixCharisma:
+		return Stats{Charisma: a.Amount}
+	case AffixCritPunchline:
+		return Stats{CritPunchline: a.Amount}
+	case AffixCritFlow:
+		return Stats{CritFlow: a.Amount}
+	case AffixCritHeavy:
+		return Stats{CritHeavy: a.Amount}
+	}
+	return Stats{}
+}
+
+// -----------------
+// Exemplaires d'objets
+// -----------------
+
+// Item est un exemplaire d'objet : l'objet du registre, sa rareté et ses propriétés tirées au sort
+type Item struct {
+	ID      string  `json:"id"`                // Identifiant de l'objet (voir items.go)
+	Rarity  Rarity  `json:"rarity,omitempty"`  // Rareté (commun par défaut)
+	Affixes []Affix `json:"affixes,omitempty"` // Propriétés tirées au sort
+}
+
+// Unique indique si l'exemplaire a été tiré au sort (il ne s'empile pas avec les autres)
+func (it Item) Unique() bool {
+	return it.Rarity != RarityCommon || len(it.Affixes) > 0
+}
+
+// Name retourne le nom affiché de l'exemplaire
+func (it Item) Name() string {
+	return ItemName(it.ID)
+}
+
+// Stats retourne les bonus de l'exemplaire porté : ceux de l'objet et ses propriétés
+func (it Item) Stats() Stats {
+	d, _ := LookupItem(it.ID)
+	total := d.Stats
+	for _, a := range it.Affixes {
+		total = total.Add(a.Stats())
+	}
+	return total
+}
+
+// AffixText décrit les propriétés tirées au sort ("" s'il n'y en a pas)
+func (it Item) AffixText() string {
+	var parts []string
+	for _, a := range it.Affixes {
+		parts = append(parts, a.Stats().String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Label retourne le nom et la rareté ("Chaîne en or [légendaire]") ; le nom seul pour un objet commun
+func (it Item) Label() string {
+	if it.Rarity == RarityCommon {
+		return it.Name()
+	}
+	return fmt.Sprintf("%s [%s]", it.Name(), it.Rarity)
+}
+
+// RollItem tire la rareté et les propriétés d'un exemplaire. Seuls les équipements en ont ;
+// luck multiplie le poids des raretés supérieures (1 : tirage normal).
+func RollItem(key string, r *mrand.Rand, luck int) Item {
+	it := Item{ID: ItemID(key)}
+	if d, ok := LookupItem(key); !ok || d.Slot == "" {
+		return it
+	}
+
+	weight := func(i int) int {
+		if rarityWeights[i].Rarity == RarityCommon {
+			return rarityWeights[i].Weight
+		}
+		return rarityWeights[i].Weight * max(luck, 1)
+	}
+	total := 0
+	for i := range rarityWeights {
+		total += weight(i)
+	}
+	pick := r.IntN(total)
+	for i := range rarityWeights {
+		pick -= weight(i)
+		if pick < 0 {
+			it.Rarity = rarityWeights[i].Rarity
+			break
+		}
+	}
+
+	// Propriétés toutes différentes, renforcées de moitié sur un légendaire
+	for _, i := range r.Perm(len(affixPool))[:rarityAffixes[it.Rarity]] {
+		a := affixPool[i]
+		amount := a.Min + r.IntN(a.Max-a.Min+1)
+		if it.Rarity == RarityLegendary {
+			amount = amount * 3 / 2
+		}
+		it.Affixes = append(it.Affixes, Affix{Stat: a.Stat, Amount: amount})
+	}
+	return it
+}
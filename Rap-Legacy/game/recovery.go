@@ -0,0 +1,78 @@
+package game
+
+import (
+	"fmt"
+	"image"
+)
+
+// -----------------
+// Ego hors combat : récupération et défaite
+// -----------------
+
+// Récupération passive : +1 ego toutes les egoRegenTicks frames (60 par seconde)
+const egoRegenTicks = 120
+
+// Pénalités de défaite (en % de l'argent et des followers)
+const (
+	defeatMoneyPenalty    = 20
+	defeatFollowerPenalty = 10
+)
+
+// Zone de repos (le studio) autour du point de spawn
+var restZone = image.Rect(SpawnX-20, SpawnY-20, SpawnX+52, SpawnY+52)
+
+// PostBattleEgo calcule l'ego du joueur après un combat.
+// Le bonus temporaire encaisse les coups en premier, donc on ne finit jamais au-dessus de l'ego de départ.
+func PostBattleEgo(egoBefore, egoInBattle int) int {
+	ego := min(egoBefore, egoInBattle)
+	if ego < 0 {
+		ego = 0
+	}
+	return ego
+}
+
+// Récupération passive de l'ego quand le joueur se balade sur la map
+func (g *Game) updateEgoRegen() {
+	if g.player == nil || g.inBattle || g.player.Ego >= g.player.Stats().Ego {
+		g.regenTimer = 0
+		return
+	}
+	g.regenTimer++
+	if g.regenTimer >= egoRegenTicks {
+		g.regenTimer = 0
+		g.player.Heal(1)
+	}
+}
+
+// Indique si le joueur est au studio (zone de repos)
+func (g *Game) inRestZone() bool {
+	if g.player == nil {
+		return false
+	}
+	playerRect := image.Rect(int(g.player.X), int(g.player.Y), int(g.player.X)+32, int(g.player.Y)+32)
+	return playerRect.Overlaps(restZone)
+}
+
+// Repos au studio : l'ego remonte au maximum
+func (g *Game) rest() {
+	healed := g.player.Heal(g.player.Stats().Ego)
+	if healed > 0 {
+		AddNotification(fmt.Sprintf("Tu te reposes au studio : +%d ego", healed))
+	} else {
+		AddNotification("Ton ego est déjà au max !")
+	}
+}
+
+// Défaite : retour au spawn, ego à zéro et perte d'une partie de l'argent et des followers
+func (g *Game) applyDefeat() {
+	lostMoney := g.Money * defeatMoneyPenalty / 100
+	lostFollowers := g.Followers * defeatFollowerPenalty / 100
+	g.Money -= lostMoney
+	g.Followers -= lostFollowers
+
+	if g.player != nil {
+		g.player.Ego = 0
+		g.player.ResetPosition()
+	}
+	AddNotification(fmt.Sprintf("Défaite... -%d$ et -%d followers. Repose-toi au studio !", lostMoney, lostFollowers))
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// -----------------
+// Replay des combats
+// -----------------
+
+// Ouvre la liste des derniers combats de la sauvegarde en cours
+func (g *Game) openReplays() {
+	records, err := LoadBattleRecords(g.saveName)
+	if err != nil {
+		log.Println("Erreur chargement journal des combats:", err)
+		records = []BattleRecord{}
+	}
+	g.replays = records
+	g.replaySelected = len(records) - 1 // Le plus récent par défaut
+	if g.replaySelected < 0 {
+		g.replaySelected = 0
+	}
+	g.replayBattle = nil
+	g.state = StateReplay
+}
+
+func (g *Game) updateReplay() {
+	// Lecture en cours : le combat rejoué gère lui-même ses entrées
+	if g.replayBattle != nil {
+		g.replayBattle.Update()
+		if g.replayBattle.IsOver() {
+			g.replayBattle = nil
+		}
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) && g.replaySelected > 0 {
+		g.replaySelected--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) && g.replaySelected < len(g.replays)-1 {
+		g.replaySelected++
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && g.replaySelected < len(g.replays) {
+		g.replayBattle = g.configureBattle(NewReplayBattle(g.replays[g.replaySelected]))
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.state = StatePlaying
+	}
+}
+
+func (g *Game) drawReplay(screen *ebiten.Image) {
+	// Lecture : même rendu que pendant le combat
+	if g.replayBattle != nil {
+		g.replayBattle.Draw(screen)
+		return
+	}
+
+	screen.Fill(color.RGBA{20, 20, 40, 255})
+
+	lines := []string{"=== Derniers combats ==="}
+	if len(g.replays) == 0 {
+		lines = append(lines, "Aucun combat enregistré.")
+	}
+	for i, rec := range g.replays {
+		result := "Défaite"
+		if rec.Winner == "player" {
+			result = "Victoire"
+		}
+		line := fmt.Sprintf("%s - vs %s - %s (%d événements)",
+			time.Unix(rec.Created, 0).Format("02/01 15:04"), rec.Enemy.Name, result, len(rec.Events))
+		if i == g.replaySelected {
+			line = "> " + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "Entrée pour revoir, ESC pour revenir")
+
+	for i, line := range lines {
+		y := 200 + i*40
+		if g.fontSmall != nil {
+			text.Draw(screen, line, g.fontSmall, 400, y, color.White)
+		} else {
+			ebitenutil.DebugPrintAt(screen, line, 400, y)
+		}
+	}
+}
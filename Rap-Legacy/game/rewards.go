@@ -0,0 +1,253 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	mrand "math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// -----------------
+// Tables de butin
+// -----------------
+
+// LootEntry est un objet qui peut tomber lors d'un tirage pondéré
+type LootEntry struct {
+	Item   string // Identifiant de l'objet (registre), vide pour rien
+	Weight int    // Poids du tirage (plus c'est grand, plus c'est fréquent)
+	Rare   bool   // Objet rare (chance augmentée par une bonne performance)
+}
+
+// LootTable décrit les récompenses d'un ennemi
+type LootTable struct {
+	MoneyMin, MoneyMax int         // Argent gagné (tiré entre min et max)
+	Followers          int         // Followers gagnés
+	Guaranteed         []string    // Objets toujours donnés
+	Rolls              int         // Nombre de tirages pondérés
+	Drops              []LootEntry // Objets possibles à chaque tirage
+}
+
+// Table utilisée pour les ennemis sans table dédiée
+var defaultLootTable = LootTable{
+	MoneyMin:  40,
+	MoneyMax:  60,
+	Followers: 100,
+	Rolls:     1,
+	Drops: []LootEntry{
+		{Item: "", Weight: 60}, // Rien
+		{Item: "randm", Weight: 30},
+		{Item: "cristalline_big", Weight: 10, Rare: true},
+	},
+}
+
+// Tables de butin par nom d'ennemi
+var lootTables = map[string]LootTable{
+	"Rival Rapper": {
+		MoneyMin:   40,
+		MoneyMax:   70,
+		Followers:  100,
+		Guaranteed: []string{"telephone"},
+		Rolls:      2,
+		Drops: []LootEntry{
+			{Item: "", Weight: 40},
+			{Item: "cigarette", Weight: 25},
+			{Item: "randm", Weight: 20},
+			{Item: "cristalline_mysterieuse", Weight: 10},
+			{Item: "cristalline_big", Weight: 5, Rare: true},
+			{Item: "chaine", Weight: 5, Rare: true},
+		},
+	},
+}
+
+// LootTableFor retourne la table de butin d'un ennemi
+func LootTableFor(enemyName string) LootTable {
+	if t, ok := lootTables[enemyName]; ok {
+		return t
+	}
+	return defaultLootTable
+}
+
+// -----------------
+// Performance et récompenses
+// -----------------
+
+// BattlePerformance résume comment le joueur a gagné
+type BattlePerformance struct {
+	Turns       int // Nombre de tours joués
+	DamageTaken int // Ego perdu pendant le combat
+	StartEgo    int // Ego au début du combat (après bonus)
+}
+
+// PerformanceOf calcule la performance du joueur à la fin d'un combat
+func PerformanceOf(b *Battle) BattlePerformance {
+	perf := BattlePerformance{Turns: b.combat.Turn}
+	for _, ev := range b.combat.Log {
+		switch {
+		case ev.Type == EventDamage && ev.Target == SidePlayer:
+			perf.DamageTaken += ev.Amount
+		case ev.Type == EventStatus && ev.Target == SidePlayer:
+			perf.StartEgo += ev.Amount
+		}
+	}
+	perf.StartEgo += b.startFighters[SidePlayer].Ego
+	return perf
+}
+
+// Grade retourne une note (S, A, B ou C) selon la rapidité et l'ego perdu
+func (p BattlePerformance) Grade() string {
+	score := 0
+	if p.Turns <= 3 {
+		score += 2
+	} else if p.Turns <= 5 {
+		score++
+	}
+	if p.DamageTaken == 0 {
+		score += 2
+	} else if p.StartEgo > 0 && p.DamageTaken*2 <= p.StartEgo {
+		score++
+	}
+	switch {
+	case score >= 4:
+		return "S"
+	case score == 3:
+		return "A"
+	case score >= 1:
+		return "B"
+	}
+	return "C"
+}
+
+// Multiplicateur (en %) appliqué à l'argent et aux followers selon la note
+var gradeMultiplier = map[string]int{"S": 200, "A": 150, "B": 100, "C": 75}
+
+// Rewards liste tout ce que le joueur gagne après une victoire
+type Rewards struct {
+	Grade     string // Note du combat
+	Money     int    // Argent gagné
+	Followers int    // Followers gagnés
+	Items     []Item // Objets obtenus (rareté et propriétés déjà tirées)
+	Rare      []bool // Objet rare de la table ? (même index que Items)
+}
+
+// RollRewards tire les récompenses d'une table selon la performance
+func RollRewards(t LootTable, perf BattlePerformance, r *mrand.Rand) Rewards {
+	grade := perf.Grade()
+	mult := gradeMultiplier[grade]
+	rw := Rewards{Grade: grade}
+
+	money := t.MoneyMin
+	if t.MoneyMax > t.MoneyMin {
+		money += r.IntN(t.MoneyMax - t.MoneyMin + 1)
+	}
+	rw.Money = money * mult / 100
+	rw.Followers = t.Followers * mult / 100
+
+	// Une note S ou A double aussi les chances d'un équipement rare ou légendaire
+	luck := 1
+	if grade == "S" || grade == "A" {
+		luck = 2
+	}
+	for _, item := range t.Guaranteed {
+		rw.Items = append(rw.Items, RollItem(item, r, luck))
+		rw.Rare = append(rw.Rare, false)
+	}
+
+	// Une note S ou A double le poids des objets rares
+	for i := 0; i < t.Rolls; i++ {
+		total := 0
+		for _, e := range t.Drops {
+			total += dropWeight(e, grade)
+		}
+		if total <= 0 {
+			break
+		}
+		pick := r.IntN(total)
+		for _, e := range t.Drops {
+			pick -= dropWeight(e, grade)
+			if pick < 0 {
+				if e.Item != "" {
+					rw.Items = append(rw.Items, RollItem(e.Item, r, luck))
+					rw.Rare = append(rw.Rare, e.Rare)
+				}
+				break
+			}
+		}
+	}
+	return rw
+}
+
+// dropWeight retourne le poids d'un objet en tenant compte de la note
+func dropWeight(e LootEntry, grade string) int {
+	if e.Rare && (grade == "S" || grade == "A") {
+		return e.Weight * 2
+	}
+	return e.Weight
+}
+
+// -----------------
+// Écran de résultats
+// -----------------
+
+// Applique les récompenses et ouvre l'écran de résultats
+func (g *Game) grantRewards(enemyName string, perf BattlePerformance, rw Rewards) {
+	g.Money += rw.Money
+	g.Followers += rw.Followers
+	for _, item := range rw.Items {
+		if g.Inventaire != nil {
+			g.Inventaire.Add(item)
+		}
+	}
+	g.lastRewards = rw
+	g.lastPerformance = perf
+	g.lastEnemyName = enemyName
+	g.state = StateBattleResults
+}
+
+func (g *Game) updateBattleResults() {
+	// inpututil : l'appui sur Entrée qui a fermé le combat ne doit pas fermer cet écran
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.state = StatePlaying // Retour à la map
+	}
+}
+
+func (g *Game) drawBattleResults(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{15, 15, 30, 255})
+
+	rw := g.lastRewards
+	perf := g.lastPerformance
+	type line struct {
+		text string
+		col  color.Color
+	}
+	lines := []line{
+		{fmt.Sprintf("=== Victoire contre %s ! ===", g.lastEnemyName), color.RGBA{255, 215, 0, 255}},
+		{fmt.Sprintf("Tours : %d   Ego perdu : %d   Note : %s", perf.Turns, perf.DamageTaken, rw.Grade), color.White},
+		{"", color.White},
+		{fmt.Sprintf("+%d $", rw.Money), color.White},
+		{fmt.Sprintf("+%d followers", rw.Followers), color.White},
+	}
+	for i, item := range rw.Items {
+		label := "+ " + item.Label()
+		if rw.Rare[i] {
+			label += " (coup de chance !)"
+		}
+		lines = append(lines, line{label, item.Rarity.Color()})
+		if affixes := item.AffixText(); affixes != "" {
+			lines = append(lines, line{"    " + affixes, item.Rarity.Color()})
+		}
+	}
+	lines = append(lines, line{"", color.White}, line{"Appuie sur Entrée pour revenir à la map", color.RGBA{200, 200, 200, 255}})
+
+	for i, l := range lines {
+		y := 250 + i*45
+		if g.fontSmall != nil {
+			text.Draw(screen, l.text, g.fontSmall, 600, y, l.col)
+		} else {
+			ebitenutil.DebugPrintAt(screen, l.text, 600, y)
+		}
+	}
+}
@@ -12,10 +12,11 @@ import (
 // Type Save (utilisé par game.go)
 // -----------------------------
 type Save struct {
-	Name      string   `json:"name"`         // Nom de la sauvegarde
-	Class     string   `json:"class"`        // Classe du joueur
-	Inventory []string `json:"inventory"`    // Liste des objets possédés
-	Created   int64    `json:"created_unix"` // Timestamp Unix de création
+	Name      string     `json:"name"`                // Nom de la sauvegarde
+	Class     string     `json:"class"`               // Classe du joueur
+	Inventory ItemStacks `json:"inventory"`           // Piles d'objets possédés (les anciennes saves ont une liste de noms)
+	Equipment Equipment  `json:"equipment,omitempty"` // Objets portés, par emplacement
+	Created   int64      `json:"created_unix"`        // Timestamp Unix de création
 	// Position du joueur
 	PlayerX float64 `json:"player_x"` // Coordonnée X du joueur
 	PlayerY float64 `json:"player_y"` // Coordonnée Y du joueur
@@ -23,6 +24,26 @@ type Save struct {
 	Ego      int `json:"ego"`      // Ego du joueur
 	Flow     int `json:"flow"`     // Flow du joueur
 	Charisma int `json:"charisma"` // Charisme du joueur
+	// Ressources du joueur
+	Money     int `json:"money"`     // Argent du joueur
+	Followers int `json:"followers"` // Followers du joueur
+	// Monde
+	Clock     GameClock                `json:"clock,omitempty"`     // Heure du jeu
+	Merchants map[string]MerchantState `json:"merchants,omitempty"` // Stock et vitrine de chaque marchand
+}
+
+// Argent d'une nouvelle partie
+const startingMoney = 100
+
+// UnmarshalJSON lit une save. Les saves créées avant l'enregistrement de l'argent repartent avec startingMoney.
+func (s *Save) UnmarshalJSON(data []byte) error {
+	type rawSave Save // Même champs, sans cette méthode
+	raw := rawSave{Money: startingMoney}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = Save(raw)
+	return nil
 }
 
 // -----------------------------
@@ -132,30 +153,32 @@ func CreateSave(name, class string) (Save, error) {
 		return Save{}, errors.New("une sauvegarde avec ce nom existe déjà")
 	}
 
-	// Détermine l'inventaire initial selon la classe
+	// Détermine l'inventaire initial selon la classe (le micro est déjà en main)
 	var inv []string
 	switch class {
 	case "Lyricistes", "lyricistes", "lyriciste":
-		inv = []string{"Micro", "Cristalline - mystérieuse", "Cigarette électronique"}
+		inv = []string{"cristalline_mysterieuse", "cigarette"}
 	case "Performeurs", "performeurs", "performer":
-		inv = []string{"Micro", "Cristalline - tonic", "Téléphone"}
+		inv = []string{"cristalline_tonic", "telephone"}
 	case "Hitmakers", "hitmakers", "hitmaker":
-		inv = []string{"Micro", "Cristalline - suspicieuse", "Téléphone"}
+		inv = []string{"cristalline_suspicieuse", "telephone"}
 	default:
-		inv = []string{"Micro"} // Inventaire par défaut
+		inv = []string{} // Inventaire par défaut
 	}
 
 	now := time.Now().Unix() // Timestamp actuel
 	newSave := Save{
-		Name:      name,  // Nom
-		Class:     class, // Classe
-		Inventory: inv,   // Inventaire
-		Created:   now,   // Date de création
-		PlayerX:   100,   // Position X initiale
-		PlayerY:   100,   // Position Y initiale
-		Ego:       100,   // Stat Ego initial
-		Flow:      10,    // Stat Flow initial
-		Charisma:  5,     // Stat Charisma initial
+		Name:      name,                              // Nom
+		Class:     class,                             // Classe
+		Inventory: NewItemStacks(inv...),             // Inventaire
+		Equipment: Equipment{SlotMic: {ID: "micro"}}, // Équipement de départ
+		Created:   now,                               // Date de création
+		PlayerX:   100,                               // Position X initiale
+		PlayerY:   100,                               // Position Y initiale
+		Ego:       100,                               // Stat Ego initial
+		Flow:      10,                                // Stat Flow initial
+		Charisma:  5,                                 // Stat Charisma initial
+		Money:     startingMoney,                     // Argent de départ
 	}
 
 	saves, err := LoadAllSaves() // Charge toutes les saves existantes
@@ -207,6 +230,9 @@ func DeleteSave(name string) error {
 	if !found { // Si non trouvée
 		return errors.New("sauvegarde introuvable")
 	}
+	if err := DeleteBattleRecords(name); err != nil { // Supprime aussi le journal des combats
+		return err
+	}
 	return SaveAll(newSaves) // Sauvegarde les autres
 }
 
@@ -0,0 +1,40 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSaveMoneyRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		data      string
+		money     int
+		followers int
+	}{
+		{"ancienne save", `{"name":"a","ego":100}`, startingMoney, 0},
+		{"fauché", `{"name":"b","money":0,"followers":12}`, 0, 12},
+		{"riche", `{"name":"c","money":950,"followers":400}`, 950, 400},
+	} {
+		var s Save
+		if err := json.Unmarshal([]byte(tc.data), &s); err != nil {
+			t.Fatalf("%s : %v", tc.name, err)
+		}
+		if s.Money != tc.money || s.Followers != tc.followers {
+			t.Errorf("%s : %d$ et %d followers, attendu %d$ et %d followers", tc.name, s.Money, s.Followers, tc.money, tc.followers)
+		}
+
+		// Une fois réécrite, la save garde les mêmes valeurs
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again Save
+		if err := json.Unmarshal(data, &again); err != nil {
+			t.Fatal(err)
+		}
+		if again.Money != s.Money || again.Followers != s.Followers {
+			t.Errorf("%s : %d$ / %d followers après réécriture", tc.name, again.Money, again.Followers)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"encoding/json" // Les réglages sont enregistrés en JSON
+	"os"            // Lecture / écriture du fichier
+	"path/filepath" // Chemin portable
+)
+
+// -----------------
+// Vitesse des combats
+// -----------------
+
+// BattleSpeed est la vitesse de lecture des combats
+type BattleSpeed int
+
+const (
+	Speed1x      BattleSpeed = iota // Vitesse normale
+	Speed2x                         // Animations et dialogues deux fois plus rapides
+	Speed4x                         // Quatre fois plus rapides
+	SpeedInstant                    // Ni animation ni dialogue : les coups s'enchaînent
+)
+
+// String retourne le nom affiché dans les réglages
+func (s BattleSpeed) String() string {
+	switch s {
+	case Speed2x:
+		return "2x"
+	case Speed4x:
+		return "4x"
+	case SpeedInstant:
+		return "instantané"
+	}
+	return "1x"
+}
+
+// Next retourne la vitesse suivante (pour faire défiler le réglage)
+func (s BattleSpeed) Next() BattleSpeed {
+	return (s + 1) % (SpeedInstant + 1)
+}
+
+// Factor retourne le multiplicateur de vitesse (0 en mode instantané)
+func (s BattleSpeed) Factor() int {
+	switch s {
+	case Speed2x:
+		return 2
+	case Speed4x:
+		return 4
+	case SpeedInstant:
+		return 0
+	}
+	return 1
+}
+
+// -----------------
+// Réglages enregistrés
+// -----------------
+
+// Settings regroupe les réglages conservés d'une partie à l'autre
+type Settings struct {
+	Volume        int         `json:"volume"`         // Volume de la musique (0–100)
+	ReducedMotion bool        `json:"reduced_motion"` // Mouvements réduits en combat
+	HardMode      bool        `json:"hard_mode"`      // Les ennemis anticipent
+	BattleSpeed   BattleSpeed `json:"battle_speed"`   // Vitesse des combats
+}
+
+const settingsFile = "settings.json" // Fichier des réglages, dans le dossier des saves
+
+// Réglages utilisés quand le fichier n'existe pas encore
+var defaultSettings = Settings{Volume: 50}
+
+// LoadSettings lit les réglages (valeurs par défaut si le fichier est absent ou invalide)
+func LoadSettings() (Settings, error) {
+	s := defaultSettings
+	data, err := os.ReadFile(filepath.Join(savesDir, settingsFile))
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return defaultSettings, err
+	}
+	// Valeurs hors limites (fichier modifié à la main) : on les ramène dans les bornes
+	s.Volume = min(max(s.Volume, 0), 100)
+	if s.BattleSpeed < Speed1x || s.BattleSpeed > SpeedInstant {
+		s.BattleSpeed = Speed1x
+	}
+	return s, nil
+}
+
+// SaveSettings écrit les réglages
+func SaveSettings(s Settings) error {
+	if err := ensureSavesPath(); err != nil {
+		return err
+	}
+	d, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(savesDir, settingsFile), d, 0o644)
+}
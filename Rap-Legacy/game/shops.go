@@ -0,0 +1,130 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"encoding/json" // Les catalogues sont décrits en JSON
+	"fmt"           // Nom des articles
+	"log"           // Erreurs de chargement
+	"os"            // Lecture du fichier
+	"sync"          // Chargement unique des catalogues
+)
+
+// -----------------
+// Catalogues des marchands
+// -----------------
+
+// Fichiers des catalogues et des marchands
+const (
+	shopsPath     = "assets/shops.json"
+	merchantsPath = "assets/merchants.json"
+)
+
+// Délai de réassort par défaut, en heures de jeu
+const defaultRestockHours = 24
+
+// ShopEntry est un article d'un catalogue : un objet ou des followers
+type ShopEntry struct {
+	Item      string `json:"item,omitempty"`      // Objet vendu (identifiant du registre)
+	ClassTag  string `json:"class_tag,omitempty"` // Vend la version de la classe du joueur dans cette famille s'il y en a une
+	Followers int    `json:"followers,omitempty"` // Followers vendus, à la place d'un objet
+	Price     int    `json:"price,omitempty"`     // Prix unitaire (0 : valeur de l'objet dans le registre)
+	Roll      bool   `json:"roll,omitempty"`      // Exemplaire unique en vitrine, rareté et propriétés tirées au sort
+	Stock     int    `json:"stock,omitempty"`     // Exemplaires disponibles à chaque réassort (0 : illimité)
+}
+
+// Key identifie l'article dans l'état du marchand enregistré dans la save
+func (e ShopEntry) Key() string {
+	if e.Followers > 0 {
+		return "followers"
+	}
+	return e.Item
+}
+
+// ShopDef est le catalogue d'un marchand
+type ShopDef struct {
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`                    // Titre du menu
+	RestockHours int         `json:"restock_hours,omitempty"` // Heures de jeu entre deux réassorts (24 par défaut)
+	Entries      []ShopEntry `json:"entries"`
+}
+
+// MerchantDef place un marchand sur la map
+type MerchantDef struct {
+	ID     string  `json:"id"`               // Clé de son état dans la save
+	Name   string  `json:"name"`             // Nom affiché sur la map
+	Shop   string  `json:"shop"`             // Catalogue vendu
+	X      float64 `json:"x"`                // Position sur la map (abscisse)
+	Y      float64 `json:"y"`                // Position sur la map (ordonnée)
+	Sprite string  `json:"sprite,omitempty"` // Image du marchand (assets/marchand.png par défaut)
+}
+
+// LoadShops charge les catalogues, indexés par identifiant
+func LoadShops(path string) (map[string]ShopDef, error) {
+	shops := map[string]ShopDef{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return shops, err
+	}
+	var list []ShopDef
+	if err := json.Unmarshal(data, &list); err != nil {
+		return shops, err
+	}
+	for _, s := range list {
+		shops[s.ID] = s
+	}
+	return shops, nil
+}
+
+var (
+	shopsOnce sync.Once
+	shops     map[string]ShopDef
+)
+
+// ShopFor retourne le catalogue d'un marchand (vide s'il n'existe pas)
+func ShopFor(id string) ShopDef {
+	shopsOnce.Do(func() {
+		var err error
+		shops, err = LoadShops(shopsPath)
+		if err != nil {
+			log.Println("Impossible de charger les catalogues :", err)
+		}
+	})
+	return shops[id]
+}
+
+// LoadMerchants charge la liste des marchands
+func LoadMerchants(path string) ([]MerchantDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []MerchantDef
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+var (
+	merchantsOnce sync.Once
+	merchantDefs  []MerchantDef
+)
+
+// MerchantDefs retourne les marchands de la map
+func MerchantDefs() []MerchantDef {
+	merchantsOnce.Do(func() {
+		var err error
+		merchantDefs, err = LoadMerchants(merchantsPath)
+		if err != nil {
+			log.Println("Impossible de charger les marchands :", err)
+		}
+	})
+	return merchantDefs
+}
+
+// Label retourne le nom de l'article pour un exemplaire donné
+func (e ShopEntry) Label(it Item) string {
+	if e.Followers > 0 {
+		return fmt.Sprintf("Followers x%d", e.Followers)
+	}
+	return it.Label()
+}
@@ -0,0 +1,98 @@
+package game // Déclare le package "game", qui contient tous les éléments du jeu
+
+import (
+	"fmt" // Pour le rapport
+	"io"  // Sortie du rapport
+	"time"
+)
+
+// -----------------
+// Simulateur de combats (sans affichage)
+// -----------------
+
+// Strategy choisit une action à partir du seul moteur de combat
+type Strategy interface {
+	Pick(c *Combat, side Side) int
+}
+
+// Nombre maximum de tours d'un combat simulé (au-delà : match nul)
+const simMaxTurns = 200
+
+// SimulateBattle joue un combat complet entre deux stratégies.
+// Retourne le gagnant, et false si le combat n'a pas fini à temps.
+func SimulateBattle(seed uint64, fighters [2]Fighter, strategies [2]Strategy) (Side, bool) {
+	c := NewCombat(seed, fighters[SidePlayer], fighters[SideEnemy])
+	next, ok := c.Order()[0], true
+	for ok && c.Turn <= simMaxTurns {
+		next, ok = c.Step(next, strategies[next].Pick(c, next))
+	}
+	switch {
+	case c.Fighters[SideEnemy].Ego <= 0 && c.Fighters[SidePlayer].Ego > 0:
+		return SidePlayer, true
+	case c.Fighters[SidePlayer].Ego <= 0 && c.Fighters[SideEnemy].Ego > 0:
+		return SideEnemy, true
+	}
+	return SidePlayer, false
+}
+
+// MatchResult compte les résultats d'une série de combats du point de vue d'une stratégie
+type MatchResult struct {
+	Games, Wins, Losses, Draws int
+}
+
+// WinRate retourne le pourcentage de victoires
+func (r MatchResult) WinRate() float64 {
+	if r.Games == 0 {
+		return 0
+	}
+	return 100 * float64(r.Wins) / float64(r.Games)
+}
+
+// SimulateMatches fait jouer games combats entre deux stratégies, en alternant les camps.
+// Les fabriques reçoivent la seed de chaque combat ; le résultat est donné pour la stratégie a.
+func SimulateMatches(games int, seed uint64, fighter Fighter, a, b func(seed uint64) Strategy) MatchResult {
+	var res MatchResult
+	for i := 0; i < games; i++ {
+		s := seed + uint64(i)
+		aSide := Side(i % 2)
+		var strategies [2]Strategy
+		strategies[aSide] = a(s)
+		strategies[aSide.Other()] = b(s)
+
+		fighters := [2]Fighter{fighter, fighter}
+		fighters[SidePlayer].Name, fighters[SideEnemy].Name = "Joueur", "Ennemi"
+		winner, finished := SimulateBattle(s, fighters, strategies)
+		res.Games++
+		switch {
+		case !finished:
+			res.Draws++
+		case winner == aSide:
+			res.Wins++
+		default:
+			res.Losses++
+		}
+	}
+	return res
+}
+
+// RunAIBenchmark compare l'IA qui anticipe à l'IA de base, pour chaque classe, et écrit le rapport
+func RunAIBenchmark(games int, w io.Writer) {
+	cfg := DefaultLookahead
+	cfg.Budget = 0 // Reproductible : seul le nombre de parties simulées limite la réflexion
+	lookahead := func(seed uint64) Strategy { return NewLookaheadController(seed, cfg) }
+	basic := func(seed uint64) Strategy { return NewAIController(seed) }
+
+	fmt.Fprintf(w, "IA qui anticipe (%d parties x %d actions) contre IA de base, %d combats par classe\n",
+		cfg.Rollouts, cfg.Depth, games)
+	for _, class := range []string{"", "Lyricistes", "Performeurs", "Hitmakers"} {
+		fighter := Fighter{Ego: 100, Flow: 10, MaxFlow: 10, Class: class}
+		start := time.Now()
+		res := SimulateMatches(games, 1, fighter, lookahead, basic)
+		name := class
+		if name == "" {
+			name = "Sans classe"
+		}
+		fmt.Fprintf(w, "%-12s %d V / %d D / %d N  (%.1f%% de victoires, %s)\n",
+			name, res.Wins, res.Losses, res.Draws, res.WinRate(), time.Since(start).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,50 @@
+package game
+
+import "testing"
+
+// lookaheadTestConfig est l'IA qui anticipe sans limite de temps : ses choix ne dépendent que de la seed
+var lookaheadTestConfig = LookaheadConfig{Rollouts: DefaultLookahead.Rollouts, Depth: DefaultLookahead.Depth}
+
+func TestLookaheadBeatsBasicAI(t *testing.T) {
+	t.Chdir("..")
+	games := 60
+	if testing.Short() {
+		games = 20
+	}
+	lookahead := func(seed uint64) Strategy { return NewLookaheadController(seed, lookaheadTestConfig) }
+	basic := func(seed uint64) Strategy { return NewAIController(seed) }
+
+	for _, class := range []string{"", "Lyricistes", "Performeurs", "Hitmakers"} {
+		fighter := Fighter{Ego: 100, Flow: 10, MaxFlow: 10, Class: class}
+		res := SimulateMatches(games, 1, fighter, lookahead, basic)
+		if res.Games != games || res.Wins+res.Losses+res.Draws != games {
+			t.Errorf("%q : %d combats comptés sur %d (%+v)", class, res.Wins+res.Losses+res.Draws, games, res)
+		}
+		if res.WinRate() <= 50 {
+			t.Errorf("%q : %.1f%% de victoires (%d V / %d D / %d N), plus de 50%% attendu",
+				class, res.WinRate(), res.Wins, res.Losses, res.Draws)
+		}
+	}
+}
+
+func TestSimulateMatchesReproducible(t *testing.T) {
+	t.Chdir("..")
+	lookahead := func(seed uint64) Strategy { return NewLookaheadController(seed, lookaheadTestConfig) }
+	basic := func(seed uint64) Strategy { return NewAIController(seed) }
+	fighter := Fighter{Ego: 100, Flow: 10, MaxFlow: 10}
+
+	if a, b := SimulateMatches(10, 7, fighter, lookahead, basic), SimulateMatches(10, 7, fighter, lookahead, basic); a != b {
+		t.Errorf("même seed, résultats différents : %+v et %+v", a, b)
+	}
+}
+
+func BenchmarkLookaheadChooseMove(b *testing.B) {
+	b.Chdir("..")
+	c := NewCombat(1,
+		Fighter{Name: "Joueur", Ego: 100, Flow: 10, MaxFlow: 10, Class: "Lyricistes"},
+		Fighter{Name: "Ennemi", Ego: 100, Flow: 10, MaxFlow: 10, Class: "Hitmakers"})
+	ai := NewLookaheadController(1, lookaheadTestConfig)
+	for b.Loop() {
+		ai.Pick(c, SideEnemy)
+	}
+}
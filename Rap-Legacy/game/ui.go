@@ -7,14 +7,16 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil" // Utilitaires de Ebiten (ex : DebugPrintAt)
 )
 
-// DrawHUD affiche les stats du joueur à l'écran
+// DrawHUD affiche les stats du joueur à l'écran (équipement compris)
 func DrawHUD(screen *ebiten.Image, player *Player) {
+	stats := player.Stats()
+
 	// Affiche l'Ego du joueur en haut à gauche (x=10, y=10)
 	ebitenutil.DebugPrintAt(screen, "Ego: "+strconv.Itoa(player.Ego), 10, 10)
 
 	// Affiche le Flow du joueur juste en dessous (x=10, y=30)
-	ebitenutil.DebugPrintAt(screen, "Flow: "+strconv.Itoa(player.Flow), 10, 30)
+	ebitenutil.DebugPrintAt(screen, "Flow: "+strconv.Itoa(stats.Flow), 10, 30)
 
 	// Affiche le Charisma du joueur juste en dessous (x=10, y=50)
-	ebitenutil.DebugPrintAt(screen, "Charisma: "+strconv.Itoa(player.Charisma), 10, 50)
+	ebitenutil.DebugPrintAt(screen, "Charisma: "+strconv.Itoa(stats.Charisma), 10, 50)
 }
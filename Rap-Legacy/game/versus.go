@@ -0,0 +1,120 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// -----------------
+// Versus local (deux joueurs, un clavier)
+// -----------------
+
+// Ouvre la sélection des personnages du versus
+func (g *Game) openVersus() {
+	saves, err := LoadAllSaves()
+	if err != nil {
+		saves = []Save{}
+	}
+	g.versusSaves = saves
+	g.versusPicks = [2]int{}
+	g.versusStep = 0
+	g.versusBattle = nil
+	g.state = StateVersus
+}
+
+func (g *Game) updateVersus() {
+	// Combat en cours : chaque joueur choisit avec ses propres touches
+	if g.versusBattle != nil {
+		g.versusBattle.Update()
+		if g.versusBattle.IsOver() {
+			winner := SidePlayer
+			if g.versusBattle.Winner == SideEnemy.String() {
+				winner = SideEnemy
+			}
+			AddNotification(g.versusBattle.combat.Fighter(winner).Name + " remporte le clash !")
+			g.versusBattle = nil
+			g.state = StateMenu
+		}
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || len(g.versusSaves) == 0 {
+		if len(g.versusSaves) == 0 {
+			AddNotification("Crée au moins une sauvegarde pour jouer en versus !")
+		}
+		g.state = StateMenu
+		return
+	}
+
+	// Chaque joueur choisit son perso avec son jeu de touches
+	keys := KeysLeft
+	if g.versusStep == 1 {
+		keys = KeysArrow
+	}
+	pick := &g.versusPicks[g.versusStep]
+	if inpututil.IsKeyJustPressed(keys.Up) {
+		*pick = (*pick + len(g.versusSaves) - 1) % len(g.versusSaves)
+	}
+	if inpututil.IsKeyJustPressed(keys.Down) {
+		*pick = (*pick + 1) % len(g.versusSaves)
+	}
+	if inpututil.IsKeyJustPressed(keys.Confirm) {
+		g.versusStep++
+		if g.versusStep == 2 {
+			left := g.versusSaves[g.versusPicks[0]]
+			right := g.versusSaves[g.versusPicks[1]]
+			g.versusBattle = g.configureBattle(NewVersusBattle(left, right))
+		}
+	}
+}
+
+func (g *Game) drawVersus(screen *ebiten.Image) {
+	if g.versusBattle != nil {
+		g.versusBattle.Draw(screen)
+		return
+	}
+
+	screen.Fill(color.RGBA{40, 10, 20, 255})
+
+	draw := func(s string, x, y int, col color.Color) {
+		if g.fontSmall != nil {
+			text.Draw(screen, s, g.fontSmall, x, y, col)
+		} else {
+			ebitenutil.DebugPrintAt(screen, s, x, y)
+		}
+	}
+
+	draw("=== VERSUS ===", 860, 150, color.RGBA{255, 215, 0, 255})
+	columns := [2]struct {
+		x     int
+		title string
+		keys  KeySet
+	}{
+		{300, "Joueur 1", KeysLeft},
+		{1100, "Joueur 2", KeysArrow},
+	}
+	for p, col := range columns {
+		title := fmt.Sprintf("%s (%s)", col.title, col.keys.Hint)
+		if p == g.versusStep {
+			title = "> " + title
+		}
+		draw(title, col.x, 250, color.White)
+		for i, s := range g.versusSaves {
+			line := fmt.Sprintf("  %s - %s (ego %d)", s.Name, s.Class, s.Ego)
+			c := color.Color(color.RGBA{160, 160, 160, 255})
+			if i == g.versusPicks[p] {
+				line = "> " + line[2:]
+				if p <= g.versusStep {
+					c = color.White
+				}
+			}
+			draw(line, col.x, 310+i*40, c)
+		}
+	}
+	draw("ESC pour revenir au menu", 300, 900, color.RGBA{200, 200, 200, 255})
+}
@@ -1,13 +1,29 @@
 package main // Déclare le package principal du jeu
 
 import (
-	"log" // Pour afficher les erreurs critiques
+	"flag" // Pour les options de la ligne de commande
+	"log"  // Pour afficher les erreurs critiques
+	"os"   // Sortie du simulateur
 
 	"github.com/hajimehoshi/ebiten/v2"      // Bibliothèque Ebiten pour le jeu
 	"github.com/projet-red_rap-legacy/game" // Import du package local "game" contenant la logique du jeu
 )
 
 func main() {
+	// -simulate-ai N : compare les IA sur N combats simulés puis quitte (sans ouvrir de fenêtre)
+	simulate := flag.Int("simulate-ai", 0, "nombre de combats simulés pour comparer les IA")
+	flag.Parse()
+
+	// Charge les attaques et les classes (assets/classes.json) avant tout le reste
+	if err := game.LoadClasses(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *simulate > 0 {
+		game.RunAIBenchmark(*simulate, os.Stdout)
+		return
+	}
+
 	// Définit l'icône de la fenêtre avec notre fonction SetGameIcon
 	SetGameIcon("assets/icon.png")
 
@@ -23,6 +39,9 @@ func main() {
 	// Définit le titre de la fenêtre
 	ebiten.SetWindowTitle("Rap Legacy")
 
+	// Le jeu gère la fermeture de la fenêtre pour enregistrer la partie avant de quitter
+	ebiten.SetWindowClosingHandled(true)
+
 	// Démarre la boucle principale du jeu
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err) // Affiche une erreur et termine si la boucle du jeu échoue